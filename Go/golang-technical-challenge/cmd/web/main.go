@@ -9,7 +9,7 @@ func main() {
 	v := config.NewViper()
 	log := config.NewLogger(v)
 	db := config.NewDatabase(v, log)
-	app := config.NewFiber(v)
+	app := config.NewFiber(v, log)
 	validate := config.NewValidator(v)
 
 	config.Bootstrap(&config.BootstrapConfig{
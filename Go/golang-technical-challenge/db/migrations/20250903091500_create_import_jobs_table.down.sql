@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_import_jobs_status_created_at;
+DROP TABLE IF EXISTS import_jobs CASCADE;
+DROP TYPE IF EXISTS import_job_status_enum CASCADE;
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TYPE import_job_status_enum AS ENUM ('PENDING', 'PROCESSING', 'COMPLETED', 'FAILED');
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id             UUID NOT NULL DEFAULT uuid_generate_v4(),
+    file_name      VARCHAR(255) NOT NULL,
+    file_data      BYTEA NOT NULL,
+    status         import_job_status_enum NOT NULL DEFAULT 'PENDING',
+    rows_total     INT NOT NULL DEFAULT 0 CHECK (rows_total >= 0),
+    rows_processed INT NOT NULL DEFAULT 0 CHECK (rows_processed >= 0),
+    rows_failed    INT NOT NULL DEFAULT 0 CHECK (rows_failed >= 0),
+    result         JSONB,
+    error_message  TEXT,
+    started_at     TIMESTAMPTZ,
+    finished_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status_created_at
+    ON import_jobs(status, created_at);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE import_jobs
+    DROP COLUMN IF EXISTS options;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE import_jobs
+    ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}';
+
+COMMIT;
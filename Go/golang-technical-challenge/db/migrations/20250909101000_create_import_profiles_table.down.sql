@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS import_profiles CASCADE;
+DROP TYPE IF EXISTS import_match_enum CASCADE;
+
+COMMIT;
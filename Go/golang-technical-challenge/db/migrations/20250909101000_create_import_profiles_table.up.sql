@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TYPE import_match_enum AS ENUM ('header', 'column');
+
+CREATE TABLE IF NOT EXISTS import_profiles (
+    name            VARCHAR(100) PRIMARY KEY CHECK (char_length(name) >= 2),
+    invoice_sheet   VARCHAR(31) NOT NULL DEFAULT 'invoice',
+    product_sheet   VARCHAR(31) NOT NULL DEFAULT 'product sold',
+    header_row      INT NOT NULL DEFAULT 1 CHECK (header_row >= 1),
+    match_by        import_match_enum NOT NULL DEFAULT 'header',
+    invoice_columns JSONB NOT NULL DEFAULT '{}',
+    product_columns JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMIT;
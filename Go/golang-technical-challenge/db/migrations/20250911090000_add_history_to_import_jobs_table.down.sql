@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_import_jobs_file_hash;
+
+ALTER TABLE import_jobs
+    DROP COLUMN IF EXISTS file_hash,
+    DROP COLUMN IF EXISTS uploaded_by,
+    DROP COLUMN IF EXISTS invoices_created,
+    DROP COLUMN IF EXISTS invoices_updated,
+    DROP COLUMN IF EXISTS invoices_skipped,
+    DROP COLUMN IF EXISTS created_invoice_nos,
+    DROP COLUMN IF EXISTS undone_at;
+
+COMMIT;
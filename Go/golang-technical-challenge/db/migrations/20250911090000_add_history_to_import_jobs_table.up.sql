@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE import_jobs
+    ADD COLUMN IF NOT EXISTS file_hash           CHAR(64),
+    ADD COLUMN IF NOT EXISTS uploaded_by         VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS invoices_created    INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS invoices_updated    INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS invoices_skipped    INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS created_invoice_nos JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS undone_at           TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_file_hash ON import_jobs (file_hash);
+
+COMMIT;
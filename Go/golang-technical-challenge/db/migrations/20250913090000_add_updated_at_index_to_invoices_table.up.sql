@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_invoices_updated_at ON invoices (updated_at);
+
+COMMIT;
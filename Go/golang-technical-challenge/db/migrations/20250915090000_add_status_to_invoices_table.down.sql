@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE invoices DROP COLUMN IF EXISTS status;
+
+DROP TYPE IF EXISTS invoice_status_enum;
+
+COMMIT;
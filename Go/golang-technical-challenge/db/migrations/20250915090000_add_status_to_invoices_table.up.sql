@@ -0,0 +1,9 @@
+BEGIN;
+
+CREATE TYPE invoice_status_enum AS ENUM ('draft', 'issued', 'paid', 'void');
+
+-- Invoices stored before statuses existed have already gone to customers.
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS status invoice_status_enum NOT NULL DEFAULT 'issued';
+ALTER TABLE invoices ALTER COLUMN status SET DEFAULT 'draft';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS payments;
+
+DROP TYPE IF EXISTS payment_method_enum;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TYPE payment_method_enum AS ENUM ('cash', 'transfer', 'card', 'cheque', 'other');
+
+CREATE TABLE IF NOT EXISTS payments (
+    id          UUID NOT NULL DEFAULT uuid_generate_v4(),
+    invoice_no  VARCHAR(50) NOT NULL REFERENCES invoices(invoice_no) ON DELETE CASCADE,
+    date        DATE NOT NULL,
+    amount      DECIMAL(12,2) NOT NULL CHECK (amount > 0),
+    method      payment_method_enum NOT NULL,
+    reference   VARCHAR(100),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_invoice_no ON payments (invoice_no);
+CREATE INDEX IF NOT EXISTS idx_payments_date ON payments (date);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_invoices_due_date;
+
+ALTER TABLE invoices DROP COLUMN IF EXISTS due_date;
+ALTER TABLE invoices DROP COLUMN IF EXISTS payment_terms;
+
+COMMIT;
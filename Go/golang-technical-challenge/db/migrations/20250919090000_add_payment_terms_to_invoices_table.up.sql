@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS payment_terms SMALLINT NOT NULL DEFAULT 0
+    CHECK (payment_terms BETWEEN 0 AND 365);
+
+-- Credit sales stored before terms existed were given the standard NET 30.
+UPDATE invoices SET payment_terms = 30 WHERE payment_type = 'CREDIT';
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS due_date DATE
+    GENERATED ALWAYS AS (date + payment_terms) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_invoices_due_date ON invoices (due_date);
+
+COMMIT;
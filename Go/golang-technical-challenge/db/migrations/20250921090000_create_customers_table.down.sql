@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_invoices_customer_id;
+
+ALTER TABLE invoices DROP COLUMN IF EXISTS customer_id;
+
+DROP TABLE IF EXISTS customers;
+
+COMMIT;
@@ -0,0 +1,54 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS customers (
+    id            UUID NOT NULL DEFAULT uuid_generate_v4(),
+    code          VARCHAR(50) NOT NULL,
+    name          VARCHAR(255) NOT NULL CHECK (char_length(name) >= 2),
+    -- The name with case, punctuation and spacing ignored, so that
+    -- "PT Maju Jaya" and "PT. Maju Jaya" are the same customer.
+    name_key      VARCHAR(255) GENERATED ALWAYS AS (btrim(lower(regexp_replace(name, '[^[:alnum:]]+', ' ', 'g')))) STORED,
+    contact_name  VARCHAR(255),
+    phone         VARCHAR(50),
+    email         VARCHAR(255),
+    address       TEXT,
+    tax_id        VARCHAR(50),
+    payment_terms SMALLINT NOT NULL DEFAULT 30 CHECK (payment_terms BETWEEN 0 AND 365),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_code ON customers (code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_name_key ON customers (name_key);
+
+-- One customer per distinct invoice customer name, named after its most
+-- used spelling and numbered in order of its first invoice.
+WITH spellings AS (
+    SELECT btrim(lower(regexp_replace(customer_name, '[^[:alnum:]]+', ' ', 'g'))) AS name_key,
+           customer_name,
+           COUNT(*) AS uses,
+           MIN(date) AS first_date
+    FROM invoices
+    GROUP BY 1, 2
+), names AS (
+    SELECT DISTINCT ON (name_key) name_key,
+           customer_name,
+           MIN(first_date) OVER (PARTITION BY name_key) AS first_date
+    FROM spellings
+    WHERE name_key <> ''
+    ORDER BY name_key, uses DESC, customer_name
+)
+INSERT INTO customers (code, name)
+SELECT 'CUST-' || lpad((row_number() OVER (ORDER BY first_date, name_key))::text, 5, '0'), customer_name
+FROM names;
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS customer_id UUID REFERENCES customers(id) ON DELETE RESTRICT;
+
+UPDATE invoices
+SET customer_id = customers.id
+FROM customers
+WHERE customers.name_key = btrim(lower(regexp_replace(invoices.customer_name, '[^[:alnum:]]+', ' ', 'g')));
+
+CREATE INDEX IF NOT EXISTS idx_invoices_customer_id ON invoices (customer_id);
+
+COMMIT;
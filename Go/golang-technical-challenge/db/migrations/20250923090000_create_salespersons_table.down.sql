@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP TABLE IF EXISTS commission_periods;
+
+DROP TABLE IF EXISTS commission_statements;
+
+DROP INDEX IF EXISTS idx_invoices_salesperson_id;
+
+ALTER TABLE invoices DROP COLUMN IF EXISTS salesperson_id;
+
+DROP TABLE IF EXISTS salespersons;
+
+DROP TYPE IF EXISTS commission_basis_enum;
+
+COMMIT;
@@ -0,0 +1,85 @@
+BEGIN;
+
+CREATE TYPE commission_basis_enum AS ENUM ('profit', 'revenue');
+
+CREATE TABLE IF NOT EXISTS salespersons (
+    id               UUID NOT NULL DEFAULT uuid_generate_v4(),
+    code             VARCHAR(50) NOT NULL,
+    name             VARCHAR(255) NOT NULL CHECK (char_length(name) >= 2),
+    -- Matched the same way as customers.name_key.
+    name_key         VARCHAR(255) GENERATED ALWAYS AS (btrim(lower(regexp_replace(name, '[^[:alnum:]]+', ' ', 'g')))) STORED,
+    email            VARCHAR(255),
+    phone            VARCHAR(50),
+    commission_basis commission_basis_enum NOT NULL DEFAULT 'profit',
+    -- [{"min_volume": "0", "rate": "2.5"}, ...] in ascending min_volume.
+    commission_tiers JSONB NOT NULL DEFAULT '[]',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_salespersons_code ON salespersons (code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_salespersons_name_key ON salespersons (name_key);
+
+-- One salesperson per distinct invoice salesperson name, named after its
+-- most used spelling and numbered in order of its first invoice. They start
+-- without commission tiers.
+WITH spellings AS (
+    SELECT btrim(lower(regexp_replace(salesperson_name, '[^[:alnum:]]+', ' ', 'g'))) AS name_key,
+           salesperson_name,
+           COUNT(*) AS uses,
+           MIN(date) AS first_date
+    FROM invoices
+    GROUP BY 1, 2
+), names AS (
+    SELECT DISTINCT ON (name_key) name_key,
+           salesperson_name,
+           MIN(first_date) OVER (PARTITION BY name_key) AS first_date
+    FROM spellings
+    WHERE name_key <> ''
+    ORDER BY name_key, uses DESC, salesperson_name
+)
+INSERT INTO salespersons (code, name)
+SELECT 'SP-' || lpad((row_number() OVER (ORDER BY first_date, name_key))::text, 5, '0'), salesperson_name
+FROM names;
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS salesperson_id UUID REFERENCES salespersons(id) ON DELETE RESTRICT;
+
+UPDATE invoices
+SET salesperson_id = salespersons.id
+FROM salespersons
+WHERE salespersons.name_key = btrim(lower(regexp_replace(invoices.salesperson_name, '[^[:alnum:]]+', ' ', 'g')));
+
+CREATE INDEX IF NOT EXISTS idx_invoices_salesperson_id ON invoices (salesperson_id);
+
+-- A closed month's statements are kept as they were calculated, with the
+-- rules and invoices they used, so later edits to salespersons or invoices
+-- cannot change what payroll was given.
+CREATE TABLE IF NOT EXISTS commission_statements (
+    id                UUID NOT NULL DEFAULT uuid_generate_v4(),
+    month             DATE NOT NULL CHECK (extract(day FROM month) = 1),
+    salesperson_id    UUID NOT NULL REFERENCES salespersons(id) ON DELETE RESTRICT,
+    salesperson_code  VARCHAR(50) NOT NULL,
+    salesperson_name  VARCHAR(255) NOT NULL,
+    commission_basis  commission_basis_enum NOT NULL,
+    commission_tiers  JSONB NOT NULL DEFAULT '[]',
+    invoice_count     INT NOT NULL DEFAULT 0,
+    revenue           DECIMAL(14,2) NOT NULL DEFAULT 0,
+    profit            DECIMAL(14,2) NOT NULL DEFAULT 0,
+    rate              DECIMAL(5,2)  NOT NULL DEFAULT 0,
+    commission        DECIMAL(14,2) NOT NULL DEFAULT 0,
+    invoices          JSONB NOT NULL DEFAULT '[]',
+    closed_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_commission_statements_month_salesperson ON commission_statements (month, salesperson_id);
+
+-- A month is closed once it has a row here, even if no salesperson earned
+-- commission in it.
+CREATE TABLE IF NOT EXISTS commission_periods (
+    month     DATE PRIMARY KEY CHECK (extract(day FROM month) = 1),
+    closed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_import_jobs_status_heartbeat_at;
+
+ALTER TABLE import_jobs
+    DROP COLUMN IF EXISTS heartbeat_at;
+
+COMMIT;
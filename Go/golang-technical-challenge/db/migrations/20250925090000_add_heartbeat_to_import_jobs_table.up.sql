@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE import_jobs
+    ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+
+UPDATE import_jobs
+SET heartbeat_at = updated_at
+WHERE status = 'PROCESSING';
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status_heartbeat_at
+    ON import_jobs(status, heartbeat_at);
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_invoice_no_fkey;
+
+ALTER TABLE payments
+    ADD CONSTRAINT payments_invoice_no_fkey
+        FOREIGN KEY (invoice_no) REFERENCES invoices(invoice_no) ON DELETE RESTRICT;
+
+COMMIT;
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"context"
 	"golang-technical-challenge/internal/delivery/http"
 	"golang-technical-challenge/internal/delivery/http/route"
+	"golang-technical-challenge/internal/delivery/worker"
 	"golang-technical-challenge/internal/repository"
 	"golang-technical-challenge/internal/usecase"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -24,16 +27,59 @@ type BootstrapConfig struct {
 func Bootstrap(config *BootstrapConfig) {
 	// add repository setup here
 	invoiceRepository := repository.NewInvoiceRepository(config.Log)
+	importJobRepository := repository.NewImportJobRepository(config.Log)
+	importProfileRepository := repository.NewImportProfileRepository(config.Log)
+	paymentRepository := repository.NewPaymentRepository(config.Log)
+	customerRepository := repository.NewCustomerRepository(config.Log)
+	salespersonRepository := repository.NewSalespersonRepository(config.Log)
+	commissionRepository := repository.NewCommissionRepository(config.Log)
 
 	// add usecase setup here
-	invoiceUseCase := usecase.NewInvoiceUseCase(config.DB, config.Log, config.Validate, invoiceRepository)
+	invoiceUseCase := usecase.NewInvoiceUseCase(config.DB, config.Log, config.Validate, invoiceRepository, customerRepository,
+		salespersonRepository)
+	importJobUseCase := usecase.NewImportJobUseCase(config.DB, config.Log, config.Validate, importJobRepository, importProfileRepository, invoiceUseCase)
+	importProfileUseCase := usecase.NewImportProfileUseCase(config.DB, config.Log, config.Validate, importProfileRepository)
+	invoicePDFUseCase := usecase.NewInvoicePDFUseCase(config.DB, config.Log, config.Validate, invoiceRepository,
+		NewInvoicePDFTemplate(config.Config, config.Log, config.Validate))
+	paymentUseCase := usecase.NewPaymentUseCase(config.DB, config.Log, config.Validate, paymentRepository, invoiceRepository)
+	customerUseCase := usecase.NewCustomerUseCase(config.DB, config.Log, config.Validate, customerRepository)
+	salespersonUseCase := usecase.NewSalespersonUseCase(config.DB, config.Log, config.Validate, salespersonRepository)
+	commissionUseCase := usecase.NewCommissionUseCase(config.DB, config.Log, config.Validate, commissionRepository, salespersonRepository)
 
 	// add controller here
 	invoiceController := http.NewInvoiceController(invoiceUseCase, config.Log)
+	importJobController := http.NewImportJobController(importJobUseCase, config.Log)
+	importProfileController := http.NewImportProfileController(importProfileUseCase, config.Log)
+	invoicePDFController := http.NewInvoicePDFController(invoicePDFUseCase, config.Log)
+	invoiceViewController := http.NewInvoiceViewController(invoiceUseCase, config.Log)
+	paymentController := http.NewPaymentController(paymentUseCase, config.Log)
+	customerController := http.NewCustomerController(customerUseCase, config.Log)
+	salespersonController := http.NewSalespersonController(salespersonUseCase, config.Log)
+	commissionController := http.NewCommissionController(commissionUseCase, config.Log)
 
 	routeConfig := route.RouteConfig{
-		App:               config.App,
-		InvoiceController: invoiceController,
+		App:                     config.App,
+		InvoiceController:       invoiceController,
+		ImportJobController:     importJobController,
+		ImportProfileController: importProfileController,
+		InvoicePDFController:    invoicePDFController,
+		InvoiceViewController:   invoiceViewController,
+		PaymentController:       paymentController,
+		CustomerController:      customerController,
+		SalespersonController:   salespersonController,
+		CommissionController:    commissionController,
 	}
 	routeConfig.Setup()
+
+	// add worker here
+	if !fiber.IsChild() {
+		importWorker := worker.NewImportWorker(
+			importJobUseCase,
+			config.Log,
+			config.Config.GetInt("IMPORT_WORKER_COUNT"),
+			time.Duration(config.Config.GetInt("IMPORT_POLL_INTERVAL"))*time.Second,
+			time.Duration(config.Config.GetInt("IMPORT_LEASE_TIMEOUT"))*time.Second,
+		)
+		importWorker.Start(context.Background())
+	}
 }
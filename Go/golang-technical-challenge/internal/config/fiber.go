@@ -1,20 +1,45 @@
 package config
 
 import (
+	"golang-technical-challenge/internal/delivery/http/view"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-func NewFiber(v *viper.Viper) *fiber.App {
+func NewFiber(v *viper.Viper, log *logrus.Logger) *fiber.App {
 	app := fiber.New(fiber.Config{
 		AppName:      v.GetString("APP_NAME"),
 		ErrorHandler: NewErrorHandler(),
 		Prefork:      v.GetBool("WEB_PREFORK"),
+		BodyLimit:    v.GetInt("WEB_BODY_LIMIT"),
+		Views:        NewViews(v, log),
 	})
 
 	return app
 }
 
+// NewViews loads the HTML templates from VIEWS_DIR. They are parsed again on
+// every render when VIEWS_RELOAD is true, which defaults to on in the
+// development environment.
+func NewViews(v *viper.Viper, log *logrus.Logger) *view.Engine {
+	dir := v.GetString("VIEWS_DIR")
+	if dir == "" {
+		dir = "templates/views"
+	}
+	reload := v.GetString("APP_ENV") == "development"
+	if v.IsSet("VIEWS_RELOAD") {
+		reload = v.GetBool("VIEWS_RELOAD")
+	}
+
+	engine := view.NewEngine(dir, reload)
+	if err := engine.Load(); err != nil {
+		log.WithError(err).WithField("dir", dir).Fatal("Failed to load views")
+	}
+	return engine
+}
+
 func NewErrorHandler() fiber.ErrorHandler {
 	return func(ctx *fiber.Ctx, err error) error {
 		code := fiber.StatusInternalServerError
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// NewInvoicePDFTemplate loads the JSON template named by INVOICE_PDF_TEMPLATE
+// over the default one. An unreadable or invalid template stops startup
+// rather than failing every PDF request later.
+func NewInvoicePDFTemplate(viper *viper.Viper, log *logrus.Logger, validate *validator.Validate) *model.InvoicePDFTemplate {
+	template := usecase.DefaultInvoicePDFTemplate()
+
+	path := viper.GetString("INVOICE_PDF_TEMPLATE")
+	if path == "" {
+		return template
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read invoice PDF template %s: %v", path, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(template); err != nil {
+		log.Fatalf("Failed to parse invoice PDF template %s: %v", path, err)
+	}
+	if err := validate.Struct(template); err != nil {
+		log.Fatalf("Invalid invoice PDF template %s: %v", path, err)
+	}
+	if template.Company.Logo != "" {
+		if _, err := os.Stat(template.Company.Logo); err != nil {
+			log.Fatalf("Failed to find logo of invoice PDF template %s: %v", path, err)
+		}
+	}
+
+	log.Infof("Loaded invoice PDF template from: %s", path)
+	return template
+}
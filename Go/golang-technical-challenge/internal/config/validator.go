@@ -1,10 +1,23 @@
 package config
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
+// NewValidator returns a validator that names fields by their JSON names, the
+// names clients send them by.
 func NewValidator(viper *viper.Viper) *validator.Validate {
-	return validator.New()
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return validate
 }
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CommissionController struct {
+	UseCase *usecase.CommissionUseCase
+	Log     *logrus.Logger
+}
+
+func NewCommissionController(useCase *usecase.CommissionUseCase, log *logrus.Logger) *CommissionController {
+	return &CommissionController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *CommissionController) Get(ctx *fiber.Ctx) error {
+	request := &model.CommissionStatementRequest{
+		Month: ctx.Params("month"),
+	}
+
+	response, err := c.UseCase.GetStatements(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("month", request.Month).Error("Failed to get commission statements")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.CommissionReportResponse]{
+		Data: response,
+	})
+}
+
+func (c *CommissionController) Close(ctx *fiber.Ctx) error {
+	request := &model.CommissionStatementRequest{
+		Month: ctx.Params("month"),
+	}
+
+	response, err := c.UseCase.Close(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("month", request.Month).Error("Failed to close commission statements")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.CommissionReportResponse]{
+		Data: response,
+	})
+}
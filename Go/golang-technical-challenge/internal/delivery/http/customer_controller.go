@@ -0,0 +1,108 @@
+package http
+
+import (
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CustomerController struct {
+	UseCase *usecase.CustomerUseCase
+	Log     *logrus.Logger
+}
+
+func NewCustomerController(useCase *usecase.CustomerUseCase, log *logrus.Logger) *CustomerController {
+	return &CustomerController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *CustomerController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateCustomerRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid JSON format for create customer")
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to create customer")
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.WebResponse[*model.CustomerResponse]{
+		Data: response,
+	})
+}
+
+func (c *CustomerController) List(ctx *fiber.Ctx) error {
+	request := &model.SearchCustomerRequest{
+		Q:    ctx.Query("q"),
+		Page: ctx.QueryInt("page", 1),
+		Size: ctx.QueryInt("size", 10),
+	}
+
+	responses, paging, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to list customers")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.CustomerResponse]{
+		Data:   responses,
+		Paging: paging,
+	})
+}
+
+func (c *CustomerController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetCustomerRequest{
+		ID: ctx.Params("id"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to get customer")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.CustomerResponse]{
+		Data: response,
+	})
+}
+
+func (c *CustomerController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateCustomerRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid JSON format for update customer")
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+	request.ID = ctx.Params("id")
+
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to update customer")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.CustomerResponse]{
+		Data: response,
+	})
+}
+
+func (c *CustomerController) Delete(ctx *fiber.Ctx) error {
+	request := &model.DeleteCustomerRequest{
+		ID: ctx.Params("id"),
+	}
+
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to delete customer")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{
+		Data: true,
+	})
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+	"mime/multipart"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ImportJobController struct {
+	UseCase *usecase.ImportJobUseCase
+	Log     *logrus.Logger
+}
+
+func NewImportJobController(useCase *usecase.ImportJobUseCase, log *logrus.Logger) *ImportJobController {
+	return &ImportJobController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *ImportJobController) Create(ctx *fiber.Ctx) error {
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to retrieve file from form-data")
+		return fiber.NewError(fiber.StatusBadRequest, "File is required")
+	}
+
+	upload := &model.ImportUpload{
+		File:       firstFormFile(form, "file"),
+		Invoices:   firstFormFile(form, "invoices"),
+		Products:   firstFormFile(form, "products"),
+		UploadedBy: ctx.FormValue("uploaded_by"),
+		Force:      ctx.QueryBool("force"),
+	}
+
+	options := &model.ImportOptions{
+		DryRun:        ctx.QueryBool("dry_run"),
+		Mode:          ctx.Query("mode", model.ImportModeBestEffort),
+		OnConflict:    ctx.Query("on_conflict", model.ImportConflictError),
+		Locale:        ctx.Query("locale"),
+		CustomerMatch: ctx.Query("customer_match"),
+		ProfileName:   ctx.FormValue("profile"),
+	}
+
+	response, err := c.UseCase.Create(ctx.UserContext(), upload, options)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to queue invoice import")
+		return err
+	}
+
+	status := fiber.StatusAccepted
+	if response.Duplicate {
+		status = fiber.StatusOK
+	}
+
+	return ctx.Status(status).JSON(model.WebResponse[*model.ImportJobResponse]{
+		Data: response,
+	})
+}
+
+func firstFormFile(form *multipart.Form, key string) *multipart.FileHeader {
+	if files := form.File[key]; len(files) > 0 {
+		return files[0]
+	}
+	return nil
+}
+
+func (c *ImportJobController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetImportJobRequest{
+		ID: ctx.Params("id"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to get import job")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ImportJobResponse]{
+		Data: response,
+	})
+}
+
+func (c *ImportJobController) List(ctx *fiber.Ctx) error {
+	request := &model.SearchImportJobRequest{
+		Status:     ctx.Query("status"),
+		UploadedBy: ctx.Query("uploaded_by"),
+		FileHash:   ctx.Query("file_hash"),
+		Page:       ctx.QueryInt("page", 1),
+		Size:       ctx.QueryInt("size", 10),
+	}
+
+	responses, paging, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to list import jobs")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.ImportJobResponse]{
+		Data:   responses,
+		Paging: paging,
+	})
+}
+
+func (c *ImportJobController) Undo(ctx *fiber.Ctx) error {
+	request := &model.GetImportJobRequest{
+		ID: ctx.Params("id"),
+	}
+
+	response, err := c.UseCase.Undo(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to undo import")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ImportJobResponse]{
+		Data: response,
+	})
+}
+
+func (c *ImportJobController) ErrorWorkbook(ctx *fiber.Ctx) error {
+	request := &model.GetImportJobRequest{
+		ID: ctx.Params("id"),
+	}
+
+	workbook, err := c.UseCase.ErrorWorkbook(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to build import error workbook")
+		return err
+	}
+
+	ctx.Attachment(workbook.FileName)
+	return ctx.Send(workbook.Content)
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ImportProfileController struct {
+	UseCase *usecase.ImportProfileUseCase
+	Log     *logrus.Logger
+}
+
+func NewImportProfileController(useCase *usecase.ImportProfileUseCase, log *logrus.Logger) *ImportProfileController {
+	return &ImportProfileController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *ImportProfileController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateImportProfileRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid JSON format for create import profile")
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to create import profile")
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.WebResponse[*model.ImportProfileResponse]{
+		Data: response,
+	})
+}
+
+func (c *ImportProfileController) List(ctx *fiber.Ctx) error {
+	responses, err := c.UseCase.List(ctx.UserContext())
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to list import profiles")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.ImportProfileResponse]{
+		Data: responses,
+	})
+}
+
+func (c *ImportProfileController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetImportProfileRequest{
+		Name: ctx.Params("name"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to get import profile")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ImportProfileResponse]{
+		Data: response,
+	})
+}
+
+func (c *ImportProfileController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateImportProfileRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid JSON format for update import profile")
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+	request.Name = ctx.Params("name")
+
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to update import profile")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ImportProfileResponse]{
+		Data: response,
+	})
+}
+
+func (c *ImportProfileController) Delete(ctx *fiber.Ctx) error {
+	request := &model.DeleteImportProfileRequest{
+		Name: ctx.Params("name"),
+	}
+
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to delete import profile")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{
+		Data: true,
+	})
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bufio"
 	"golang-technical-challenge/internal/model"
 	"golang-technical-challenge/internal/usecase"
 
@@ -20,44 +21,144 @@ func NewInvoiceController(useCase *usecase.InvoiceUseCase, log *logrus.Logger) *
 	}
 }
 
-func (c *InvoiceController) Import(ctx *fiber.Ctx) error {
-	fileHeader, err := ctx.FormFile("file")
+func (c *InvoiceController) GetInvoices(ctx *fiber.Ctx) error {
+	request := searchInvoiceRequest(ctx, 10)
+
+	response, err := c.UseCase.GetInvoices(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.WithError(err).Error("Failed to retrieve file from form-data")
-		return fiber.NewError(fiber.StatusBadRequest, "File is required")
+		c.Log.WithError(err).Error("Failed to get invoices")
+		return err
 	}
 
-	results, err := c.UseCase.ImportInvoices(ctx.UserContext(), fileHeader)
+	return ctx.JSON(model.WebResponse[*model.InvoiceListResponse]{
+		Data: response,
+	})
+}
+
+// searchInvoiceRequest reads the listing filters and paging from the query
+// string, defaulting to the first page of size invoices.
+func searchInvoiceRequest(ctx *fiber.Ctx, size int) *model.SearchInvoiceRequest {
+	return &model.SearchInvoiceRequest{
+		InvoiceFilter: model.InvoiceFilter{
+			Date:            ctx.Query("date"),
+			DateFrom:        ctx.Query("date_from"),
+			DateTo:          ctx.Query("date_to"),
+			CustomerID:      ctx.Query("customer_id"),
+			CustomerName:    ctx.Query("customer_name"),
+			SalespersonID:   ctx.Query("salesperson_id"),
+			SalespersonName: ctx.Query("salesperson_name"),
+			PaymentType:     ctx.Query("payment_type"),
+			Status:          ctx.Query("status"),
+			MinTotal:        ctx.Query("min_total"),
+			MaxTotal:        ctx.Query("max_total"),
+			Q:               ctx.Query("q"),
+		},
+		Sort:   ctx.Query("sort"),
+		Cursor: ctx.Query("cursor"),
+		Page:   ctx.QueryInt("page", 1),
+		Size:   ctx.QueryInt("size", size),
+	}
+}
+
+func (c *InvoiceController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetInvoiceRequest{
+		InvoiceNo: ctx.Params("invoiceNo"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.WithError(err).Error("Failed to process invoice import")
-		return fiber.ErrInternalServerError
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to get invoice")
+		return err
 	}
 
-	// Handle import errors
-	if errs, ok := results.([]model.ImportError); ok {
-		return ctx.JSON(model.WebResponse[[]model.ImportError]{Data: errs})
+	return ctx.JSON(model.WebResponse[*model.InvoiceResponse]{
+		Data: response,
+	})
+}
+
+func (c *InvoiceController) Export(ctx *fiber.Ctx) error {
+	request := searchInvoiceRequest(ctx, 0)
+
+	export, err := c.UseCase.ExportInvoices(ctx.UserContext(), &request.InvoiceFilter)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to export invoices")
+		return err
 	}
 
-	// Success response
-	return ctx.JSON(model.WebResponse[any]{Data: results})
+	return c.sendExport(ctx, export)
 }
 
-func (c *InvoiceController) GetInvoices(ctx *fiber.Ctx) error {
-	date := ctx.Query("date")
-	page := ctx.QueryInt("page", 1)
-	size := ctx.QueryInt("size", 10)
+func (c *InvoiceController) ExportCSV(ctx *fiber.Ctx) error {
+	return c.exportLines(ctx, model.InvoiceExportCSV)
+}
 
-	response, err := c.UseCase.GetInvoices(ctx.UserContext(), date, page, size)
+func (c *InvoiceController) ExportNDJSON(ctx *fiber.Ctx) error {
+	return c.exportLines(ctx, model.InvoiceExportNDJSON)
+}
+
+func (c *InvoiceController) exportLines(ctx *fiber.Ctx, format string) error {
+	request := &model.ExportInvoicesRequest{
+		Format:       format,
+		DateFrom:     ctx.Query("date_from"),
+		DateTo:       ctx.Query("date_to"),
+		UpdatedSince: ctx.Query("updated_since"),
+	}
+
+	export, err := c.UseCase.ExportInvoiceLines(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.WithError(err).Error("Failed to get invoices")
+		c.Log.WithError(err).WithField("format", format).Error("Failed to export invoices")
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[*model.InvoiceListResponse]{
+	return c.sendExport(ctx, export)
+}
+
+func (c *InvoiceController) Aging(ctx *fiber.Ctx) error {
+	request := &model.AgingReportRequest{
+		AsOf: ctx.Query("as_of"),
+	}
+
+	response, err := c.UseCase.GetAging(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to get aging report")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AgingReportResponse]{
 		Data: response,
 	})
 }
 
+func (c *InvoiceController) ExportAging(ctx *fiber.Ctx) error {
+	request := &model.AgingReportRequest{
+		AsOf: ctx.Query("as_of"),
+	}
+
+	export, err := c.UseCase.ExportAging(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to export aging report")
+		return err
+	}
+
+	return c.sendExport(ctx, export)
+}
+
+// sendExport streams export into the response body after the handler
+// returns. Anything that can fail with a proper error response has to be
+// checked before this is called; a failure while writing only cuts the
+// body short.
+func (c *InvoiceController) sendExport(ctx *fiber.Ctx, export *model.InvoiceExport) error {
+	ctx.Attachment(export.FileName)
+	ctx.Set(fiber.HeaderContentType, export.ContentType)
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer export.Close()
+		if _, err := export.WriteTo(w); err != nil {
+			c.Log.WithError(err).WithField("file_name", export.FileName).Error("Failed to write invoice export")
+		}
+	})
+	return nil
+}
+
 func (c *InvoiceController) Create(ctx *fiber.Ctx) error {
 	request := new(model.CreateInvoiceRequest)
 
@@ -97,6 +198,38 @@ func (c *InvoiceController) Update(ctx *fiber.Ctx) error {
 	})
 }
 
+func (c *InvoiceController) Issue(ctx *fiber.Ctx) error {
+	return c.changeStatus(ctx, model.InvoiceStatusIssued)
+}
+
+func (c *InvoiceController) Pay(ctx *fiber.Ctx) error {
+	return c.changeStatus(ctx, model.InvoiceStatusPaid)
+}
+
+func (c *InvoiceController) Void(ctx *fiber.Ctx) error {
+	return c.changeStatus(ctx, model.InvoiceStatusVoid)
+}
+
+func (c *InvoiceController) changeStatus(ctx *fiber.Ctx, status string) error {
+	request := &model.ChangeInvoiceStatusRequest{
+		InvoiceNo: ctx.Params("invoiceNo"),
+		Status:    status,
+	}
+
+	response, err := c.UseCase.ChangeStatus(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithFields(logrus.Fields{
+			"invoice_no": request.InvoiceNo,
+			"status":     status,
+		}).Error("Failed to change invoice status")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.InvoiceResponse]{
+		Data: response,
+	})
+}
+
 func (c *InvoiceController) Delete(ctx *fiber.Ctx) error {
 	invoiceNo := ctx.Params("invoiceNo")
 
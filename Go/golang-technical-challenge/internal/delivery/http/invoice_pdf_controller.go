@@ -0,0 +1,37 @@
+package http
+
+import (
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type InvoicePDFController struct {
+	UseCase *usecase.InvoicePDFUseCase
+	Log     *logrus.Logger
+}
+
+func NewInvoicePDFController(useCase *usecase.InvoicePDFUseCase, log *logrus.Logger) *InvoicePDFController {
+	return &InvoicePDFController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *InvoicePDFController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetInvoiceRequest{
+		InvoiceNo: ctx.Params("invoiceNo"),
+	}
+
+	document, err := c.UseCase.Render(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to render invoice PDF")
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/pdf")
+	ctx.Set(fiber.HeaderContentDisposition, `inline; filename="`+document.FileName+`"`)
+	return ctx.Send(document.Content)
+}
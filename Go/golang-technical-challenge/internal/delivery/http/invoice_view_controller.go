@@ -0,0 +1,54 @@
+package http
+
+import (
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// InvoiceViewController serves printable HTML pages rendered from the
+// templates in VIEWS_DIR.
+type InvoiceViewController struct {
+	UseCase *usecase.InvoiceUseCase
+	Log     *logrus.Logger
+}
+
+func NewInvoiceViewController(useCase *usecase.InvoiceUseCase, log *logrus.Logger) *InvoiceViewController {
+	return &InvoiceViewController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *InvoiceViewController) List(ctx *fiber.Ctx) error {
+	request := searchInvoiceRequest(ctx, 100)
+
+	response, err := c.UseCase.GetInvoices(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to get invoices")
+		return err
+	}
+
+	return ctx.Render("invoices.html", fiber.Map{
+		"Filter": request.InvoiceFilter,
+		"List":   response,
+	})
+}
+
+func (c *InvoiceViewController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetInvoiceRequest{
+		InvoiceNo: ctx.Params("invoiceNo"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to get invoice")
+		return err
+	}
+
+	return ctx.Render("invoice.html", fiber.Map{
+		"Invoice": response,
+	})
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentController struct {
+	UseCase *usecase.PaymentUseCase
+	Log     *logrus.Logger
+}
+
+func NewPaymentController(useCase *usecase.PaymentUseCase, log *logrus.Logger) *PaymentController {
+	return &PaymentController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *PaymentController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreatePaymentRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid JSON format for create payment")
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+	request.InvoiceNo = ctx.Params("invoiceNo")
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to create payment")
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.WebResponse[*model.PaymentResponse]{
+		Data: response,
+	})
+}
+
+func (c *PaymentController) List(ctx *fiber.Ctx) error {
+	request := &model.ListPaymentRequest{
+		InvoiceNo: ctx.Params("invoiceNo"),
+	}
+
+	responses, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to list payments")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.PaymentResponse]{
+		Data: responses,
+	})
+}
@@ -7,8 +7,16 @@ import (
 )
 
 type RouteConfig struct {
-	App               *fiber.App
-	InvoiceController *http.InvoiceController
+	App                     *fiber.App
+	InvoiceController       *http.InvoiceController
+	ImportJobController     *http.ImportJobController
+	ImportProfileController *http.ImportProfileController
+	InvoicePDFController    *http.InvoicePDFController
+	InvoiceViewController   *http.InvoiceViewController
+	PaymentController       *http.PaymentController
+	CustomerController      *http.CustomerController
+	SalespersonController   *http.SalespersonController
+	CommissionController    *http.CommissionController
 }
 
 func (c *RouteConfig) Setup() {
@@ -16,9 +24,44 @@ func (c *RouteConfig) Setup() {
 }
 
 func (c *RouteConfig) SetupAuthRoute() {
-	c.App.Post("/api/invoices/import", c.InvoiceController.Import)
+	c.App.Post("/api/invoices/import", c.ImportJobController.Create)
+	c.App.Get("/api/invoices/imports", c.ImportJobController.List)
+	c.App.Get("/api/invoices/imports/:id", c.ImportJobController.Get)
+	c.App.Get("/api/invoices/imports/:id/errors.xlsx", c.ImportJobController.ErrorWorkbook)
+	c.App.Post("/api/invoices/imports/:id/undo", c.ImportJobController.Undo)
+	c.App.Get("/api/import-profiles", c.ImportProfileController.List)
+	c.App.Post("/api/import-profiles", c.ImportProfileController.Create)
+	c.App.Get("/api/import-profiles/:name", c.ImportProfileController.Get)
+	c.App.Put("/api/import-profiles/:name", c.ImportProfileController.Update)
+	c.App.Delete("/api/import-profiles/:name", c.ImportProfileController.Delete)
 	c.App.Get("/api/invoices", c.InvoiceController.GetInvoices)
+	c.App.Get("/api/invoices/export.xlsx", c.InvoiceController.Export)
+	c.App.Get("/api/invoices/export.csv", c.InvoiceController.ExportCSV)
+	c.App.Get("/api/invoices/export.ndjson", c.InvoiceController.ExportNDJSON)
+	c.App.Get("/api/invoices/aging", c.InvoiceController.Aging)
+	c.App.Get("/api/invoices/aging.xlsx", c.InvoiceController.ExportAging)
 	c.App.Post("/api/invoices", c.InvoiceController.Create)
+	c.App.Get("/api/invoices/:invoiceNo", c.InvoiceController.Get)
+	c.App.Get("/api/invoices/:invoiceNo/pdf", c.InvoicePDFController.Get)
+	c.App.Post("/api/invoices/:invoiceNo/issue", c.InvoiceController.Issue)
+	c.App.Post("/api/invoices/:invoiceNo/pay", c.InvoiceController.Pay)
+	c.App.Post("/api/invoices/:invoiceNo/void", c.InvoiceController.Void)
+	c.App.Get("/api/invoices/:invoiceNo/payments", c.PaymentController.List)
+	c.App.Post("/api/invoices/:invoiceNo/payments", c.PaymentController.Create)
 	c.App.Put("/api/invoices/:invoiceNo", c.InvoiceController.Update)
 	c.App.Delete("/api/invoices/:invoiceNo", c.InvoiceController.Delete)
+	c.App.Get("/api/customers", c.CustomerController.List)
+	c.App.Post("/api/customers", c.CustomerController.Create)
+	c.App.Get("/api/customers/:id", c.CustomerController.Get)
+	c.App.Put("/api/customers/:id", c.CustomerController.Update)
+	c.App.Delete("/api/customers/:id", c.CustomerController.Delete)
+	c.App.Get("/api/salespersons", c.SalespersonController.List)
+	c.App.Post("/api/salespersons", c.SalespersonController.Create)
+	c.App.Get("/api/salespersons/:id", c.SalespersonController.Get)
+	c.App.Put("/api/salespersons/:id", c.SalespersonController.Update)
+	c.App.Delete("/api/salespersons/:id", c.SalespersonController.Delete)
+	c.App.Get("/api/commissions/:month", c.CommissionController.Get)
+	c.App.Post("/api/commissions/:month/close", c.CommissionController.Close)
+	c.App.Get("/view/invoices", c.InvoiceViewController.List)
+	c.App.Get("/view/invoices/:invoiceNo", c.InvoiceViewController.Get)
 }
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type SalespersonController struct {
+	UseCase *usecase.SalespersonUseCase
+	Log     *logrus.Logger
+}
+
+func NewSalespersonController(useCase *usecase.SalespersonUseCase, log *logrus.Logger) *SalespersonController {
+	return &SalespersonController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *SalespersonController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateSalespersonRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid JSON format for create salesperson")
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to create salesperson")
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.WebResponse[*model.SalespersonResponse]{
+		Data: response,
+	})
+}
+
+func (c *SalespersonController) List(ctx *fiber.Ctx) error {
+	request := &model.SearchSalespersonRequest{
+		Q:    ctx.Query("q"),
+		Page: ctx.QueryInt("page", 1),
+		Size: ctx.QueryInt("size", 10),
+	}
+
+	responses, paging, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to list salespersons")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.SalespersonResponse]{
+		Data:   responses,
+		Paging: paging,
+	})
+}
+
+func (c *SalespersonController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetSalespersonRequest{
+		ID: ctx.Params("id"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to get salesperson")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.SalespersonResponse]{
+		Data: response,
+	})
+}
+
+func (c *SalespersonController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateSalespersonRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid JSON format for update salesperson")
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+	request.ID = ctx.Params("id")
+
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to update salesperson")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.SalespersonResponse]{
+		Data: response,
+	})
+}
+
+func (c *SalespersonController) Delete(ctx *fiber.Ctx) error {
+	request := &model.DeleteSalespersonRequest{
+		ID: ctx.Params("id"),
+	}
+
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to delete salesperson")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{
+		Data: true,
+	})
+}
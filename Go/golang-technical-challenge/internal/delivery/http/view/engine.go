@@ -0,0 +1,67 @@
+package view
+
+import (
+	"fmt"
+	"golang-technical-challenge/internal/model/converter"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Engine renders the html/template files in a directory for fiber's
+// ctx.Render. Every *.html file is parsed into one set, so pages can share
+// partials defined in any file, and each page is executed by its file name.
+// With reload set the directory is parsed again before every render, so
+// template edits show up without restarting the server.
+type Engine struct {
+	dir       string
+	reload    bool
+	mu        sync.RWMutex
+	templates *template.Template
+}
+
+func NewEngine(dir string, reload bool) *Engine {
+	return &Engine{dir: dir, reload: reload}
+}
+
+func (e *Engine) Load() error {
+	templates, err := template.New("").Funcs(funcs).ParseGlob(filepath.Join(e.dir, "*.html"))
+	if err != nil {
+		return fmt.Errorf("cannot parse templates in %s: %w", e.dir, err)
+	}
+
+	e.mu.Lock()
+	e.templates = templates
+	e.mu.Unlock()
+	return nil
+}
+
+// Render executes the template called name. Layouts are not used; pages
+// include the shared partials themselves.
+func (e *Engine) Render(w io.Writer, name string, data interface{}, layouts ...string) error {
+	if e.reload {
+		if err := e.Load(); err != nil {
+			return err
+		}
+	}
+
+	e.mu.RLock()
+	templates := e.templates
+	e.mu.RUnlock()
+	return templates.ExecuteTemplate(w, name, data)
+}
+
+var funcs = template.FuncMap{
+	"money": money,
+	"date":  func(t time.Time) string { return t.Format("02 Jan 2006") },
+	"add":   func(a, b int) int { return a + b },
+}
+
+// money formats value with two decimals and comma thousands separators.
+func money(value decimal.Decimal) string {
+	return converter.FormatAmount(value, ",", ".")
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"golang-technical-challenge/internal/usecase"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ImportWorker struct {
+	UseCase      *usecase.ImportJobUseCase
+	Log          *logrus.Logger
+	Concurrency  int
+	PollInterval time.Duration
+	LeaseTimeout time.Duration
+}
+
+func NewImportWorker(useCase *usecase.ImportJobUseCase, log *logrus.Logger, concurrency int, pollInterval, leaseTimeout time.Duration) *ImportWorker {
+	if concurrency <= 0 {
+		concurrency = 2
+	}
+	if pollInterval <= 0 {
+		pollInterval = 2 * time.Second
+	}
+	if leaseTimeout <= 0 {
+		leaseTimeout = 5 * time.Minute
+	}
+
+	return &ImportWorker{
+		UseCase:      useCase,
+		Log:          log,
+		Concurrency:  concurrency,
+		PollInterval: pollInterval,
+		LeaseTimeout: leaseTimeout,
+	}
+}
+
+// Start launches the worker pool together with a loop that requeues jobs
+// whose worker stopped sending heartbeats for LeaseTimeout, on this server
+// or any other. It returns immediately; workers stop when ctx is cancelled.
+func (w *ImportWorker) Start(ctx context.Context) {
+	go w.requeue(ctx)
+
+	for i := 0; i < w.Concurrency; i++ {
+		go w.run(ctx, i+1)
+	}
+
+	w.Log.WithFields(logrus.Fields{
+		"concurrency":   w.Concurrency,
+		"lease_timeout": w.LeaseTimeout,
+	}).Info("Import worker started")
+}
+
+// requeue looks for interrupted jobs at startup and then every half lease,
+// so a job is back in the queue at most one and a half leases after its
+// server stopped.
+func (w *ImportWorker) requeue(ctx context.Context) {
+	ticker := time.NewTicker(w.LeaseTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		if err := w.UseCase.RequeueInterrupted(ctx, w.LeaseTimeout); err != nil {
+			w.Log.WithError(err).Error("Failed to requeue interrupted import jobs")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *ImportWorker) run(ctx context.Context, id int) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := w.UseCase.ProcessNext(ctx)
+			if err != nil {
+				w.Log.WithError(err).WithField("worker", id).Error("Failed to process import job")
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CommissionStatement is a salesperson's commission for a closed month as it
+// was calculated when the month was closed. It copies the salesperson's code,
+// name and rules, and lists the invoices it counted as the JSON of a
+// []model.CommissionInvoice.
+type CommissionStatement struct {
+	ID              string          `gorm:"column:id;type:uuid;default:uuid_generate_v4();primaryKey"`
+	Month           time.Time       `gorm:"column:month;type:date;not null;uniqueIndex:idx_commission_statements_month_salesperson"`
+	SalespersonID   string          `gorm:"column:salesperson_id;type:uuid;not null;uniqueIndex:idx_commission_statements_month_salesperson"`
+	SalespersonCode string          `gorm:"column:salesperson_code;type:varchar(50);not null"`
+	SalespersonName string          `gorm:"column:salesperson_name;type:varchar(255);not null"`
+	CommissionBasis string          `gorm:"column:commission_basis;type:commission_basis_enum;not null"`
+	CommissionTiers string          `gorm:"column:commission_tiers;type:jsonb;not null;default:'[]'"`
+	InvoiceCount    int             `gorm:"column:invoice_count;not null;default:0"`
+	Revenue         decimal.Decimal `gorm:"column:revenue;type:decimal(14,2);not null;default:0"`
+	Profit          decimal.Decimal `gorm:"column:profit;type:decimal(14,2);not null;default:0"`
+	Rate            decimal.Decimal `gorm:"column:rate;type:decimal(5,2);not null;default:0"`
+	Commission      decimal.Decimal `gorm:"column:commission;type:decimal(14,2);not null;default:0"`
+	Invoices        string          `gorm:"column:invoices;type:jsonb;not null;default:'[]'"`
+	ClosedAt        time.Time       `gorm:"column:closed_at;type:timestamptz;default:now();not null"`
+}
+
+func (CommissionStatement) TableName() string {
+	return "commission_statements"
+}
+
+// CommissionPeriod marks a month whose commission statements are closed.
+type CommissionPeriod struct {
+	Month    time.Time `gorm:"column:month;type:date;primaryKey"`
+	ClosedAt time.Time `gorm:"column:closed_at;type:timestamptz;default:now();not null"`
+}
+
+func (CommissionPeriod) TableName() string {
+	return "commission_periods"
+}
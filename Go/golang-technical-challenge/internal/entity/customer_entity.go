@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+type Customer struct {
+	ID           string    `gorm:"column:id;type:uuid;default:uuid_generate_v4();primaryKey"`
+	Code         string    `gorm:"column:code;type:varchar(50);not null;uniqueIndex"`
+	Name         string    `gorm:"column:name;type:varchar(255);not null;check:char_length(name) >= 2"`
+	NameKey      string    `gorm:"column:name_key;type:varchar(255);->;uniqueIndex"`
+	ContactName  *string   `gorm:"column:contact_name;type:varchar(255)"`
+	Phone        *string   `gorm:"column:phone;type:varchar(50)"`
+	Email        *string   `gorm:"column:email;type:varchar(255)"`
+	Address      *string   `gorm:"column:address;type:text"`
+	TaxID        *string   `gorm:"column:tax_id;type:varchar(50)"`
+	PaymentTerms int       `gorm:"column:payment_terms;type:smallint;not null;default:30;check:payment_terms BETWEEN 0 AND 365"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamptz;default:now();not null"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamptz;default:now();not null"`
+}
+
+func (Customer) TableName() string {
+	return "customers"
+}
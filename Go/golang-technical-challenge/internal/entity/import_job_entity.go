@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+const (
+	ImportJobStatusPending    = "PENDING"
+	ImportJobStatusProcessing = "PROCESSING"
+	ImportJobStatusCompleted  = "COMPLETED"
+	ImportJobStatusFailed     = "FAILED"
+)
+
+type ImportJob struct {
+	ID                string     `gorm:"column:id;type:uuid;default:uuid_generate_v4();primaryKey"`
+	FileName          string     `gorm:"column:file_name;type:varchar(255);not null"`
+	FileData          []byte     `gorm:"column:file_data;type:bytea;not null"`
+	FileHash          string     `gorm:"column:file_hash;type:char(64)"`
+	UploadedBy        *string    `gorm:"column:uploaded_by;type:varchar(255)"`
+	Status            string     `gorm:"column:status;type:import_job_status_enum;not null;default:PENDING"`
+	RowsTotal         int        `gorm:"column:rows_total;not null;default:0"`
+	RowsProcessed     int        `gorm:"column:rows_processed;not null;default:0"`
+	RowsFailed        int        `gorm:"column:rows_failed;not null;default:0"`
+	InvoicesCreated   int        `gorm:"column:invoices_created;not null;default:0"`
+	InvoicesUpdated   int        `gorm:"column:invoices_updated;not null;default:0"`
+	InvoicesSkipped   int        `gorm:"column:invoices_skipped;not null;default:0"`
+	CreatedInvoiceNos string     `gorm:"column:created_invoice_nos;type:jsonb;not null;default:'[]'"`
+	Options           string     `gorm:"column:options;type:jsonb;not null;default:'{}'"`
+	Result            *string    `gorm:"column:result;type:jsonb"`
+	ErrorMessage      *string    `gorm:"column:error_message"`
+	StartedAt         *time.Time `gorm:"column:started_at;type:timestamptz"`
+	HeartbeatAt       *time.Time `gorm:"column:heartbeat_at;type:timestamptz"`
+	FinishedAt        *time.Time `gorm:"column:finished_at;type:timestamptz"`
+	UndoneAt          *time.Time `gorm:"column:undone_at;type:timestamptz"`
+	CreatedAt         time.Time  `gorm:"column:created_at;type:timestamptz;default:now();not null"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at;type:timestamptz;default:now();not null"`
+}
+
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type ImportProfile struct {
+	Name           string    `gorm:"column:name;type:varchar(100);primaryKey;check:char_length(name) >= 2"`
+	InvoiceSheet   string    `gorm:"column:invoice_sheet;type:varchar(31);not null;default:invoice"`
+	ProductSheet   string    `gorm:"column:product_sheet;type:varchar(31);not null;default:product sold"`
+	HeaderRow      int       `gorm:"column:header_row;not null;default:1;check:header_row >= 1"`
+	MatchBy        string    `gorm:"column:match_by;type:import_match_enum;not null;default:header"`
+	InvoiceColumns string    `gorm:"column:invoice_columns;type:jsonb;not null;default:'{}'"`
+	ProductColumns string    `gorm:"column:product_columns;type:jsonb;not null;default:'{}'"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:timestamptz;default:now();not null"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;type:timestamptz;default:now();not null"`
+}
+
+func (ImportProfile) TableName() string {
+	return "import_profiles"
+}
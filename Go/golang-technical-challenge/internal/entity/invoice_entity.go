@@ -1,20 +1,42 @@
 package entity
 
-import "time"
+import (
+	"time"
 
+	"github.com/shopspring/decimal"
+)
+
+// Invoice keeps CustomerName and SalespersonName as snapshots of the names
+// when it was made, unchanged if the customer or salesperson is renamed
+// later. Invoices stored before customers and salespersons existed may have
+// no CustomerID or SalespersonID.
 type Invoice struct {
 	InvoiceNo       string    `gorm:"column:invoice_no;type:varchar(50);primaryKey"`
 	Date            time.Time `gorm:"column:date;type:date;not null"`
+	CustomerID      *string   `gorm:"column:customer_id;type:uuid;index"`
 	CustomerName    string    `gorm:"column:customer_name;type:varchar(255);not null;check:char_length(customer_name) >= 2"`
+	SalespersonID   *string   `gorm:"column:salesperson_id;type:uuid;index"`
 	SalespersonName string    `gorm:"column:salesperson_name;type:varchar(255);not null;check:char_length(salesperson_name) >= 2"`
 	PaymentType     string    `gorm:"column:payment_type;type:payment_enum;not null"`
 	Notes           *string   `gorm:"column:notes;check:notes IS NULL OR char_length(notes) >= 5"`
+	PaymentTerms    int       `gorm:"column:payment_terms;type:smallint;not null;default:0;check:payment_terms BETWEEN 0 AND 365"`
+	Status          string    `gorm:"column:status;type:invoice_status_enum;not null;default:draft"`
 	CreatedAt       time.Time `gorm:"column:created_at;type:timestamptz;default:now();not null"`
 	UpdatedAt       time.Time `gorm:"column:updated_at;type:timestamptz;default:now();not null"`
 
+	// PaidAmount is the sum of the invoice's payments. It is not stored, and
+	// is only filled in by the repository queries that select it.
+	PaidAmount decimal.Decimal `gorm:"column:paid_amount;->;-:migration"`
+
 	Products []Product `gorm:"foreignKey:InvoiceNo;references:InvoiceNo;constraint:OnDelete:CASCADE"`
 }
 
 func (Invoice) TableName() string {
 	return "invoices"
 }
+
+// DueDate is the date payment is due, PaymentTerms days after the invoice
+// date. The database stores it in the generated due_date column.
+func (i *Invoice) DueDate() time.Time {
+	return i.Date.AddDate(0, 0, i.PaymentTerms)
+}
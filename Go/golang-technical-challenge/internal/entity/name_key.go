@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameKey lowercases name and collapses every run of characters other than
+// letters and digits into a single space, as the generated name_key columns
+// of customers and salespersons do, so that "PT. Maju  Jaya" and
+// "pt maju jaya" match.
+func NameKey(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Payment struct {
+	ID        string          `gorm:"column:id;type:uuid;default:uuid_generate_v4();primaryKey"`
+	InvoiceNo string          `gorm:"column:invoice_no;type:varchar(50);not null;index"`
+	Date      time.Time       `gorm:"column:date;type:date;not null"`
+	Amount    decimal.Decimal `gorm:"column:amount;type:decimal(12,2);not null;check:amount > 0"`
+	Method    string          `gorm:"column:method;type:payment_method_enum;not null"`
+	Reference *string         `gorm:"column:reference;type:varchar(100)"`
+	CreatedAt time.Time       `gorm:"column:created_at;type:timestamptz;default:now();not null"`
+	UpdatedAt time.Time       `gorm:"column:updated_at;type:timestamptz;default:now();not null"`
+}
+
+func (Payment) TableName() string {
+	return "payments"
+}
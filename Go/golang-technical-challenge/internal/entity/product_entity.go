@@ -22,3 +22,19 @@ type Product struct {
 func (Product) TableName() string {
 	return "products"
 }
+
+// Revenue is the line amount charged, TotalPrice per item times Quantity,
+// without rounding.
+func (p *Product) Revenue() decimal.Decimal {
+	return p.TotalPrice.Mul(decimal.NewFromInt(int64(p.Quantity)))
+}
+
+// Cost is the line cost, TotalCost per item times Quantity, without rounding.
+func (p *Product) Cost() decimal.Decimal {
+	return p.TotalCost.Mul(decimal.NewFromInt(int64(p.Quantity)))
+}
+
+// Profit is Revenue less Cost.
+func (p *Product) Profit() decimal.Decimal {
+	return p.Revenue().Sub(p.Cost())
+}
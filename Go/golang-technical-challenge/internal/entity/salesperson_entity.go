@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Salesperson keeps its commission tiers as the JSON of a
+// []model.CommissionTier.
+type Salesperson struct {
+	ID              string    `gorm:"column:id;type:uuid;default:uuid_generate_v4();primaryKey"`
+	Code            string    `gorm:"column:code;type:varchar(50);not null;uniqueIndex"`
+	Name            string    `gorm:"column:name;type:varchar(255);not null;check:char_length(name) >= 2"`
+	NameKey         string    `gorm:"column:name_key;type:varchar(255);->;uniqueIndex"`
+	Email           *string   `gorm:"column:email;type:varchar(255)"`
+	Phone           *string   `gorm:"column:phone;type:varchar(50)"`
+	CommissionBasis string    `gorm:"column:commission_basis;type:commission_basis_enum;not null;default:profit"`
+	CommissionTiers string    `gorm:"column:commission_tiers;type:jsonb;not null;default:'[]'"`
+	CreatedAt       time.Time `gorm:"column:created_at;type:timestamptz;default:now();not null"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;type:timestamptz;default:now();not null"`
+}
+
+func (Salesperson) TableName() string {
+	return "salespersons"
+}
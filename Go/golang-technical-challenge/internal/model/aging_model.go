@@ -0,0 +1,34 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+// AgingReportRequest ages the outstanding balances of CREDIT invoices as of
+// AsOf, or as of today when it is empty.
+type AgingReportRequest struct {
+	AsOf string `json:"as_of" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// AgingBuckets label the columns of an aging report by the number of days
+// past the due date, "current" being balances that are not due yet.
+var AgingBuckets = []string{"current", "1-30", "31-60", "61-90", "90+"}
+
+// AgingBalance splits the outstanding balance of Invoices invoices by how
+// long they are overdue. ID is set on the balances of registered customers.
+type AgingBalance struct {
+	ID         string          `json:"id,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	Invoices   int             `json:"invoices"`
+	Current    decimal.Decimal `json:"current"`
+	Days1To30  decimal.Decimal `json:"days_1_30"`
+	Days31To60 decimal.Decimal `json:"days_31_60"`
+	Days61To90 decimal.Decimal `json:"days_61_90"`
+	Over90     decimal.Decimal `json:"days_over_90"`
+	Total      decimal.Decimal `json:"total"`
+}
+
+type AgingReportResponse struct {
+	AsOf         string         `json:"as_of"`
+	Customers    []AgingBalance `json:"customers"`
+	Salespersons []AgingBalance `json:"salespersons"`
+	Total        AgingBalance   `json:"total"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CommissionStatementRequest asks for the statements of a month, given as
+// YYYY-MM.
+type CommissionStatementRequest struct {
+	Month string `json:"-" validate:"required,datetime=2006-01"`
+}
+
+// CommissionInvoice is an invoice counted towards a commission, with its
+// revenue and profit summed over its products the way GetSummary sums them.
+type CommissionInvoice struct {
+	InvoiceNo     string          `json:"invoice_no"`
+	Date          time.Time       `json:"date"`
+	SalespersonID string          `json:"-"`
+	CustomerName  string          `json:"customer_name"`
+	Status        string          `json:"status"`
+	Revenue       decimal.Decimal `json:"revenue"`
+	Profit        decimal.Decimal `json:"profit"`
+}
+
+// CommissionStatement is a salesperson's commission for a month. Volume, the
+// revenue, picks the tier whose Rate is applied to the revenue or profit
+// named by CommissionBasis. The salesperson's details and rules are those in
+// force when the statement was calculated.
+type CommissionStatement struct {
+	SalespersonID   string              `json:"salesperson_id"`
+	SalespersonCode string              `json:"salesperson_code"`
+	SalespersonName string              `json:"salesperson_name"`
+	CommissionBasis string              `json:"commission_basis"`
+	CommissionTiers []CommissionTier    `json:"commission_tiers"`
+	InvoiceCount    int                 `json:"invoice_count"`
+	Revenue         decimal.Decimal     `json:"revenue"`
+	Profit          decimal.Decimal     `json:"profit"`
+	Rate            decimal.Decimal     `json:"rate"`
+	Commission      decimal.Decimal     `json:"commission"`
+	Invoices        []CommissionInvoice `json:"invoices"`
+}
+
+// CommissionReportResponse lists the statements of every salesperson with
+// issued or paid invoices in Month. Once the month is Closed the statements
+// are returned as they were stored at ClosedAt instead of being recalculated.
+type CommissionReportResponse struct {
+	Month           string                `json:"month"`
+	Closed          bool                  `json:"closed"`
+	ClosedAt        *time.Time            `json:"closed_at,omitempty"`
+	Statements      []CommissionStatement `json:"statements"`
+	TotalCommission decimal.Decimal       `json:"total_commission"`
+}
@@ -0,0 +1,35 @@
+package converter
+
+import (
+	"encoding/json"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+)
+
+func CommissionStatementToResponse(statement *entity.CommissionStatement) *model.CommissionStatement {
+	response := &model.CommissionStatement{
+		SalespersonID:   statement.SalespersonID,
+		SalespersonCode: statement.SalespersonCode,
+		SalespersonName: statement.SalespersonName,
+		CommissionBasis: statement.CommissionBasis,
+		CommissionTiers: CommissionTiers(statement.CommissionTiers),
+		InvoiceCount:    statement.InvoiceCount,
+		Revenue:         statement.Revenue,
+		Profit:          statement.Profit,
+		Rate:            statement.Rate,
+		Commission:      statement.Commission,
+		Invoices:        []model.CommissionInvoice{},
+	}
+
+	_ = json.Unmarshal([]byte(statement.Invoices), &response.Invoices)
+
+	return response
+}
+
+func CommissionStatementsToResponseList(statements []entity.CommissionStatement) []model.CommissionStatement {
+	responses := make([]model.CommissionStatement, len(statements))
+	for i, statement := range statements {
+		responses[i] = *CommissionStatementToResponse(&statement)
+	}
+	return responses
+}
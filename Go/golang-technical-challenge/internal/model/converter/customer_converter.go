@@ -0,0 +1,30 @@
+package converter
+
+import (
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+)
+
+func CustomerToResponse(customer *entity.Customer) *model.CustomerResponse {
+	return &model.CustomerResponse{
+		ID:           customer.ID,
+		Code:         customer.Code,
+		Name:         customer.Name,
+		ContactName:  customer.ContactName,
+		Phone:        customer.Phone,
+		Email:        customer.Email,
+		Address:      customer.Address,
+		TaxID:        customer.TaxID,
+		PaymentTerms: customer.PaymentTerms,
+		CreatedAt:    customer.CreatedAt,
+		UpdatedAt:    customer.UpdatedAt,
+	}
+}
+
+func CustomersToResponseList(customers []entity.Customer) []model.CustomerResponse {
+	responses := make([]model.CustomerResponse, len(customers))
+	for i, customer := range customers {
+		responses[i] = *CustomerToResponse(&customer)
+	}
+	return responses
+}
@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"encoding/json"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+)
+
+func ImportJobToResponse(job *entity.ImportJob) *model.ImportJobResponse {
+	response := &model.ImportJobResponse{
+		ID:              job.ID,
+		FileName:        job.FileName,
+		FileHash:        job.FileHash,
+		UploadedBy:      job.UploadedBy,
+		Status:          job.Status,
+		RowsTotal:       job.RowsTotal,
+		RowsProcessed:   job.RowsProcessed,
+		RowsFailed:      job.RowsFailed,
+		InvoicesCreated: job.InvoicesCreated,
+		InvoicesUpdated: job.InvoicesUpdated,
+		InvoicesSkipped: job.InvoicesSkipped,
+		Error:           job.ErrorMessage,
+		StartedAt:       job.StartedAt,
+		FinishedAt:      job.FinishedAt,
+		UndoneAt:        job.UndoneAt,
+		CreatedAt:       job.CreatedAt,
+		UpdatedAt:       job.UpdatedAt,
+	}
+
+	if job.Options != "" {
+		_ = json.Unmarshal([]byte(job.Options), &response.Options)
+	}
+
+	if job.CreatedInvoiceNos != "" {
+		_ = json.Unmarshal([]byte(job.CreatedInvoiceNos), &response.CreatedInvoiceNos)
+	}
+
+	if job.StartedAt != nil && job.FinishedAt != nil {
+		duration := job.FinishedAt.Sub(*job.StartedAt).Milliseconds()
+		response.DurationMs = &duration
+	}
+
+	if job.Result != nil {
+		result := new(model.ImportResult)
+		if err := json.Unmarshal([]byte(*job.Result), result); err == nil {
+			response.Result = result
+		}
+	}
+
+	return response
+}
+
+func ImportJobsToResponseList(jobs []entity.ImportJob) []model.ImportJobResponse {
+	responses := make([]model.ImportJobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = *ImportJobToResponse(&job)
+	}
+	return responses
+}
@@ -0,0 +1,34 @@
+package converter
+
+import (
+	"encoding/json"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+)
+
+func ImportProfileToResponse(profile *entity.ImportProfile) *model.ImportProfileResponse {
+	response := &model.ImportProfileResponse{
+		Name:           profile.Name,
+		InvoiceSheet:   profile.InvoiceSheet,
+		ProductSheet:   profile.ProductSheet,
+		HeaderRow:      profile.HeaderRow,
+		MatchBy:        profile.MatchBy,
+		InvoiceColumns: map[string]string{},
+		ProductColumns: map[string]string{},
+		CreatedAt:      profile.CreatedAt,
+		UpdatedAt:      profile.UpdatedAt,
+	}
+
+	_ = json.Unmarshal([]byte(profile.InvoiceColumns), &response.InvoiceColumns)
+	_ = json.Unmarshal([]byte(profile.ProductColumns), &response.ProductColumns)
+
+	return response
+}
+
+func ImportProfilesToResponseList(profiles []entity.ImportProfile) []model.ImportProfileResponse {
+	responses := make([]model.ImportProfileResponse, len(profiles))
+	for i, profile := range profiles {
+		responses[i] = *ImportProfileToResponse(&profile)
+	}
+	return responses
+}
@@ -3,19 +3,46 @@ package converter
 import (
 	"golang-technical-challenge/internal/entity"
 	"golang-technical-challenge/internal/model"
+
+	"github.com/shopspring/decimal"
 )
 
+// InvoiceToResponse sums the line amounts of the invoice's products into its
+// subtotal, total cost and profit. Only an issued CREDIT invoice has an
+// outstanding balance: drafts are not owed yet, CASH invoices are paid at
+// the sale, and paid and void invoices are settled.
 func InvoiceToResponse(invoice *entity.Invoice) *model.InvoiceResponse {
+	subtotal, totalCost := decimal.Zero, decimal.Zero
+	for i := range invoice.Products {
+		subtotal = subtotal.Add(invoice.Products[i].Revenue())
+		totalCost = totalCost.Add(invoice.Products[i].Cost())
+	}
+
+	outstanding := decimal.Zero
+	if invoice.Status == model.InvoiceStatusIssued && invoice.PaymentType == "CREDIT" {
+		outstanding = subtotal.Sub(invoice.PaidAmount)
+	}
+
 	return &model.InvoiceResponse{
 		InvoiceNo:       invoice.InvoiceNo,
 		Date:            invoice.Date,
+		CustomerID:      invoice.CustomerID,
 		CustomerName:    invoice.CustomerName,
+		SalespersonID:   invoice.SalespersonID,
 		SalespersonName: invoice.SalespersonName,
 		PaymentType:     invoice.PaymentType,
 		Notes:           invoice.Notes,
+		PaymentTerms:    invoice.PaymentTerms,
+		DueDate:         invoice.DueDate(),
+		Status:          invoice.Status,
 		CreatedAt:       invoice.CreatedAt,
 		UpdatedAt:       invoice.UpdatedAt,
 		Products:        ProductsToResponseList(invoice.Products),
+		Subtotal:        RoundAmount(subtotal),
+		TotalCost:       RoundAmount(totalCost),
+		Profit:          RoundAmount(subtotal.Sub(totalCost)),
+		PaidAmount:      RoundAmount(invoice.PaidAmount),
+		Outstanding:     RoundAmount(outstanding),
 	}
 }
 
@@ -0,0 +1,27 @@
+package converter
+
+import (
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+)
+
+func PaymentToResponse(payment *entity.Payment) *model.PaymentResponse {
+	return &model.PaymentResponse{
+		ID:        payment.ID,
+		InvoiceNo: payment.InvoiceNo,
+		Date:      payment.Date,
+		Amount:    payment.Amount,
+		Method:    payment.Method,
+		Reference: payment.Reference,
+		CreatedAt: payment.CreatedAt,
+		UpdatedAt: payment.UpdatedAt,
+	}
+}
+
+func PaymentsToResponseList(payments []entity.Payment) []model.PaymentResponse {
+	responses := make([]model.PaymentResponse, len(payments))
+	for i, payment := range payments {
+		responses[i] = *PaymentToResponse(&payment)
+	}
+	return responses
+}
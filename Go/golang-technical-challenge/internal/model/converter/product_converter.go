@@ -3,18 +3,66 @@ package converter
 import (
 	"golang-technical-challenge/internal/entity"
 	"golang-technical-challenge/internal/model"
+	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
+// AmountPlaces is the number of decimal places computed amounts are
+// rounded to.
+const AmountPlaces = 2
+
+// RoundAmount applies the rounding policy of every computed amount in a
+// response: the exact result of the decimal arithmetic is rounded once, to
+// AmountPlaces, with halves rounded away from zero. Invoice totals are summed
+// from the exact line amounts and only rounded at the end.
+func RoundAmount(amount decimal.Decimal) decimal.Decimal {
+	return amount.Round(AmountPlaces)
+}
+
+// FormatAmount writes amount with AmountPlaces decimals for printing, such
+// as 1,234,567.89 with "," and "." as the thousands and decimal separators.
+func FormatAmount(amount decimal.Decimal, thousands, decimalPoint string) string {
+	text := amount.StringFixed(AmountPlaces)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	whole, fraction, _ := strings.Cut(text, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + decimalPoint + fraction
+}
+
+// ProductToResponse adds the line amounts of product: revenue and cost are
+// the per-item price and cost times quantity, and the margin is the profit
+// as a percentage of the revenue.
 func ProductToResponse(product *entity.Product) model.ProductResponse {
-	return model.ProductResponse{
-		ID:         product.ID,
-		ItemName:   product.ItemName,
-		Quantity:   product.Quantity,
-		TotalCost:  product.TotalCost,
-		TotalPrice: product.TotalPrice,
-		CreatedAt:  product.CreatedAt,
-		UpdatedAt:  product.UpdatedAt,
+	response := model.ProductResponse{
+		ID:          product.ID,
+		ItemName:    product.ItemName,
+		Quantity:    product.Quantity,
+		TotalCost:   product.TotalCost,
+		TotalPrice:  product.TotalPrice,
+		CreatedAt:   product.CreatedAt,
+		UpdatedAt:   product.UpdatedAt,
+		LineRevenue: RoundAmount(product.Revenue()),
+		LineCost:    RoundAmount(product.Cost()),
+		LineProfit:  RoundAmount(product.Profit()),
 	}
+
+	if revenue := product.Revenue(); !revenue.IsZero() {
+		margin := RoundAmount(product.Profit().Mul(decimal.NewFromInt(100)).Div(revenue))
+		response.MarginPercent = &margin
+	}
+
+	return response
 }
 
 func ProductsToResponseList(products []entity.Product) []model.ProductResponse {
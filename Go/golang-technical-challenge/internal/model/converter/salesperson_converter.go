@@ -0,0 +1,37 @@
+package converter
+
+import (
+	"encoding/json"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+)
+
+func SalespersonToResponse(salesperson *entity.Salesperson) *model.SalespersonResponse {
+	return &model.SalespersonResponse{
+		ID:              salesperson.ID,
+		Code:            salesperson.Code,
+		Name:            salesperson.Name,
+		Email:           salesperson.Email,
+		Phone:           salesperson.Phone,
+		CommissionBasis: salesperson.CommissionBasis,
+		CommissionTiers: CommissionTiers(salesperson.CommissionTiers),
+		CreatedAt:       salesperson.CreatedAt,
+		UpdatedAt:       salesperson.UpdatedAt,
+	}
+}
+
+func SalespersonsToResponseList(salespersons []entity.Salesperson) []model.SalespersonResponse {
+	responses := make([]model.SalespersonResponse, len(salespersons))
+	for i, salesperson := range salespersons {
+		responses[i] = *SalespersonToResponse(&salesperson)
+	}
+	return responses
+}
+
+// CommissionTiers decodes the commission tiers stored on a salesperson or
+// statement.
+func CommissionTiers(encoded string) []model.CommissionTier {
+	tiers := []model.CommissionTier{}
+	_ = json.Unmarshal([]byte(encoded), &tiers)
+	return tiers
+}
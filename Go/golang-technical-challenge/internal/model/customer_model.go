@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+type CustomerResponse struct {
+	ID           string    `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	ContactName  *string   `json:"contact_name,omitempty"`
+	Phone        *string   `json:"phone,omitempty"`
+	Email        *string   `json:"email,omitempty"`
+	Address      *string   `json:"address,omitempty"`
+	TaxID        *string   `json:"tax_id,omitempty"`
+	PaymentTerms int       `json:"payment_terms"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreateCustomerRequest registers a customer. PaymentTerms are the days its
+// CREDIT invoices are given to be paid unless an invoice sets its own,
+// DefaultPaymentTerms when empty.
+type CreateCustomerRequest struct {
+	Code         string  `json:"code" validate:"required,max=50"`
+	Name         string  `json:"name" validate:"required,min=2,max=255"`
+	ContactName  *string `json:"contact_name,omitempty" validate:"omitempty,max=255"`
+	Phone        *string `json:"phone,omitempty" validate:"omitempty,max=50"`
+	Email        *string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Address      *string `json:"address,omitempty"`
+	TaxID        *string `json:"tax_id,omitempty" validate:"omitempty,max=50"`
+	PaymentTerms *int    `json:"payment_terms,omitempty" validate:"omitempty,min=0,max=365"`
+}
+
+type UpdateCustomerRequest struct {
+	ID           string  `json:"-" validate:"required,uuid"`
+	Code         string  `json:"code" validate:"required,max=50"`
+	Name         string  `json:"name" validate:"required,min=2,max=255"`
+	ContactName  *string `json:"contact_name,omitempty" validate:"omitempty,max=255"`
+	Phone        *string `json:"phone,omitempty" validate:"omitempty,max=50"`
+	Email        *string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Address      *string `json:"address,omitempty"`
+	TaxID        *string `json:"tax_id,omitempty" validate:"omitempty,max=50"`
+	PaymentTerms *int    `json:"payment_terms,omitempty" validate:"omitempty,min=0,max=365"`
+}
+
+type GetCustomerRequest struct {
+	ID string `json:"-" validate:"required,uuid"`
+}
+
+type DeleteCustomerRequest struct {
+	ID string `json:"-" validate:"required,uuid"`
+}
+
+// SearchCustomerRequest pages through customers by name. Q matches the code,
+// name or tax ID.
+type SearchCustomerRequest struct {
+	Q    string `json:"q" validate:"max=255"`
+	Page int    `json:"page" validate:"min=1"`
+	Size int    `json:"size" validate:"min=1,max=100"`
+}
+
+// How the importer resolves the customer column of an invoice row to a
+// customer.
+const (
+	// CustomerMatchNormalized ignores case, punctuation and spacing, so
+	// "PT. Maju Jaya" finds "PT Maju Jaya".
+	CustomerMatchNormalized = "normalized"
+	// CustomerMatchExact requires the name exactly as registered.
+	CustomerMatchExact = "exact"
+	// CustomerMatchCode reads the column as the customer code.
+	CustomerMatchCode = "code"
+)
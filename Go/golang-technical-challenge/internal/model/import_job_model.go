@@ -0,0 +1,115 @@
+package model
+
+import (
+	"mime/multipart"
+	"time"
+)
+
+type ImportJobResponse struct {
+	ID                string        `json:"id"`
+	FileName          string        `json:"file_name"`
+	FileHash          string        `json:"file_hash"`
+	UploadedBy        *string       `json:"uploaded_by,omitempty"`
+	Status            string        `json:"status"`
+	Duplicate         bool          `json:"duplicate,omitempty"`
+	RowsTotal         int           `json:"rows_total"`
+	RowsProcessed     int           `json:"rows_processed"`
+	RowsFailed        int           `json:"rows_failed"`
+	InvoicesCreated   int           `json:"invoices_created"`
+	InvoicesUpdated   int           `json:"invoices_updated"`
+	InvoicesSkipped   int           `json:"invoices_skipped"`
+	CreatedInvoiceNos []string      `json:"created_invoice_nos,omitempty"`
+	Options           ImportOptions `json:"options"`
+	Result            *ImportResult `json:"result,omitempty"`
+	Error             *string       `json:"error,omitempty"`
+	DurationMs        *int64        `json:"duration_ms,omitempty"`
+	StartedAt         *time.Time    `json:"started_at,omitempty"`
+	FinishedAt        *time.Time    `json:"finished_at,omitempty"`
+	UndoneAt          *time.Time    `json:"undone_at,omitempty"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+type GetImportJobRequest struct {
+	ID string `json:"-" validate:"required,uuid"`
+}
+
+type SearchImportJobRequest struct {
+	Status     string `json:"status" validate:"omitempty,oneof=PENDING PROCESSING COMPLETED FAILED"`
+	UploadedBy string `json:"uploaded_by" validate:"max=255"`
+	FileHash   string `json:"file_hash" validate:"omitempty,len=64,hexadecimal"`
+	Page       int    `json:"page" validate:"min=1"`
+	Size       int    `json:"size" validate:"min=1,max=100"`
+}
+
+const (
+	ImportModeAtomic     = "atomic"
+	ImportModeBestEffort = "best_effort"
+)
+
+// What to do with rows whose invoice number already exists in the database.
+const (
+	ImportConflictSkip    = "skip"
+	ImportConflictError   = "error"
+	ImportConflictReplace = "replace"
+)
+
+type ImportErrorWorkbook struct {
+	FileName string
+	Content  []byte
+}
+
+const (
+	ImportFormatXLSX = "xlsx"
+	ImportFormatCSV  = "csv"
+)
+
+// ImportUpload carries either a single workbook or zip archive in File, or a
+// pair of CSV/TSV files in Invoices and Products. An upload matching an
+// earlier import is only queued again when Force is set.
+type ImportUpload struct {
+	File       *multipart.FileHeader
+	Invoices   *multipart.FileHeader
+	Products   *multipart.FileHeader
+	UploadedBy string `validate:"max=255"`
+	Force      bool
+}
+
+// ImportOptions are stored on the job when it is queued. The selected profile
+// is copied in so later edits to it do not change how a queued job is read.
+// Locale decides how numbers and dates written as text are read; without one
+// numbers take "." as the decimal point and no thousands separators, and
+// day/month dates that read either way round are reported as ambiguous.
+// CustomerMatch is the CustomerMatch rule resolving the customer column,
+// CustomerMatchNormalized when empty.
+type ImportOptions struct {
+	Format        string                 `json:"format"`
+	DryRun        bool                   `json:"dry_run"`
+	Mode          string                 `json:"mode" validate:"required,oneof=atomic best_effort"`
+	OnConflict    string                 `json:"on_conflict" validate:"required,oneof=skip error replace"`
+	Locale        string                 `json:"locale,omitempty" validate:"omitempty,oneof=en-US en-GB id-ID de-DE fr-FR"`
+	CustomerMatch string                 `json:"customer_match,omitempty" validate:"omitempty,oneof=normalized exact code"`
+	ProfileName   string                 `json:"-" validate:"max=100"`
+	Profile       *ImportProfileResponse `json:"profile,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun      bool              `json:"dry_run"`
+	Mode        string            `json:"mode"`
+	RolledBack  bool              `json:"rolled_back"`
+	Invoices    []InvoiceResponse `json:"invoices"`
+	WouldCreate []InvoiceResponse `json:"would_create,omitempty"`
+	WouldUpdate []InvoiceResponse `json:"would_update,omitempty"`
+	Created     int               `json:"created"`
+	Updated     int               `json:"updated"`
+	Skipped     int               `json:"skipped"`
+	TotalProfit string            `json:"total_profit"`
+	TotalCash   string            `json:"total_cash"`
+	Errors      []ImportError     `json:"errors"`
+	// Warnings reports rows that were imported but may need a look, such
+	// as invoices of a customer that is not registered.
+	Warnings []ImportError `json:"warnings,omitempty"`
+
+	// CreatedInvoiceNos is stored on the job itself rather than in the result.
+	CreatedInvoiceNos []string `json:"-"`
+}
@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+const (
+	ImportMatchByHeader = "header"
+	ImportMatchByColumn = "column"
+)
+
+// Field names used as keys of ImportProfile column mappings.
+const (
+	ImportFieldInvoiceNo       = "invoice_no"
+	ImportFieldDate            = "date"
+	ImportFieldCustomerName    = "customer_name"
+	ImportFieldSalespersonName = "salesperson_name"
+	ImportFieldPaymentType     = "payment_type"
+	ImportFieldNotes           = "notes"
+	ImportFieldPaymentTerms    = "payment_terms"
+	ImportFieldItemName        = "item_name"
+	ImportFieldQuantity        = "quantity"
+	ImportFieldTotalCost       = "total_cost"
+	ImportFieldTotalPrice      = "total_price"
+)
+
+type ImportProfileResponse struct {
+	Name           string            `json:"name"`
+	InvoiceSheet   string            `json:"invoice_sheet"`
+	ProductSheet   string            `json:"product_sheet"`
+	HeaderRow      int               `json:"header_row"`
+	MatchBy        string            `json:"match_by"`
+	InvoiceColumns map[string]string `json:"invoice_columns"`
+	ProductColumns map[string]string `json:"product_columns"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+type CreateImportProfileRequest struct {
+	Name           string            `json:"name" validate:"required,min=2,max=100"`
+	InvoiceSheet   string            `json:"invoice_sheet" validate:"omitempty,max=31"`
+	ProductSheet   string            `json:"product_sheet" validate:"omitempty,max=31"`
+	HeaderRow      int               `json:"header_row" validate:"omitempty,min=1"`
+	MatchBy        string            `json:"match_by" validate:"omitempty,oneof=header column"`
+	InvoiceColumns map[string]string `json:"invoice_columns" validate:"dive,keys,oneof=invoice_no date customer_name salesperson_name payment_type notes payment_terms,endkeys,required"`
+	ProductColumns map[string]string `json:"product_columns" validate:"dive,keys,oneof=invoice_no item_name quantity total_cost total_price,endkeys,required"`
+}
+
+type UpdateImportProfileRequest struct {
+	Name           string            `json:"-" validate:"required"`
+	InvoiceSheet   string            `json:"invoice_sheet" validate:"omitempty,max=31"`
+	ProductSheet   string            `json:"product_sheet" validate:"omitempty,max=31"`
+	HeaderRow      int               `json:"header_row" validate:"omitempty,min=1"`
+	MatchBy        string            `json:"match_by" validate:"omitempty,oneof=header column"`
+	InvoiceColumns map[string]string `json:"invoice_columns" validate:"dive,keys,oneof=invoice_no date customer_name salesperson_name payment_type notes payment_terms,endkeys,required"`
+	ProductColumns map[string]string `json:"product_columns" validate:"dive,keys,oneof=invoice_no item_name quantity total_cost total_price,endkeys,required"`
+}
+
+type GetImportProfileRequest struct {
+	Name string `json:"-" validate:"required"`
+}
+
+type DeleteImportProfileRequest struct {
+	Name string `json:"-" validate:"required"`
+}
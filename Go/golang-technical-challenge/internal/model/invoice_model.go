@@ -1,52 +1,218 @@
 package model
 
 import (
+	"io"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type InvoiceResponse struct {
 	InvoiceNo       string            `json:"invoice_no"`
 	Date            time.Time         `json:"date"`
+	CustomerID      *string           `json:"customer_id"`
 	CustomerName    string            `json:"customer_name"`
+	SalespersonID   *string           `json:"salesperson_id"`
 	SalespersonName string            `json:"salesperson_name"`
 	PaymentType     string            `json:"payment_type"`
 	Notes           *string           `json:"notes,omitempty"`
+	PaymentTerms    int               `json:"payment_terms"`
+	DueDate         time.Time         `json:"due_date"`
+	Status          string            `json:"status"`
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
 	Products        []ProductResponse `json:"products"`
+	Subtotal        decimal.Decimal   `json:"subtotal"`
+	TotalCost       decimal.Decimal   `json:"total_cost"`
+	Profit          decimal.Decimal   `json:"profit"`
+	PaidAmount      decimal.Decimal   `json:"paid_amount"`
+	Outstanding     decimal.Decimal   `json:"outstanding_balance"`
 }
 
 type InvoiceListResponse struct {
 	Invoices    []InvoiceResponse `json:"invoices"`
 	TotalProfit string            `json:"total_profit"`
 	TotalCash   string            `json:"total_cash"`
-	Paging      PageMetadata      `json:"paging"`
+	// TotalCollected sums the CREDIT payments received within the date
+	// filters, whatever the date of the invoices they pay.
+	TotalCollected string       `json:"total_collected"`
+	Paging         PageMetadata `json:"paging"`
+}
+
+// InvoiceFilter selects the invoices of a listing. Every field is optional
+// and set fields are combined with AND. Date matches a single day, MinTotal
+// and MaxTotal bound the sum of the invoice's line totals, and Q searches
+// the notes and item names.
+type InvoiceFilter struct {
+	Date            string `json:"date" validate:"omitempty,datetime=2006-01-02"`
+	DateFrom        string `json:"date_from" validate:"omitempty,datetime=2006-01-02"`
+	DateTo          string `json:"date_to" validate:"omitempty,datetime=2006-01-02"`
+	CustomerID      string `json:"customer_id" validate:"omitempty,uuid"`
+	CustomerName    string `json:"customer_name" validate:"omitempty,max=255"`
+	SalespersonID   string `json:"salesperson_id" validate:"omitempty,uuid"`
+	SalespersonName string `json:"salesperson_name" validate:"omitempty,max=255"`
+	PaymentType     string `json:"payment_type" validate:"omitempty,oneof=CASH CREDIT"`
+	Status          string `json:"status" validate:"omitempty,oneof=draft issued paid void"`
+	MinTotal        string `json:"min_total" validate:"omitempty,numeric"`
+	MaxTotal        string `json:"max_total" validate:"omitempty,numeric"`
+	Q               string `json:"q" validate:"omitempty,max=255"`
+}
+
+// SearchInvoiceRequest pages through the invoices matching its filter. Sort
+// is a comma-separated list of InvoiceSortKeys, each prefixed with "-" to
+// sort descending, e.g. "-date,customer_name,total".
+//
+// Cursor, a next_cursor or prev_cursor token from an earlier page, pages by
+// position instead of Page.
+type SearchInvoiceRequest struct {
+	InvoiceFilter
+	Sort   string `json:"sort"`
+	Cursor string `json:"cursor"`
+	Page   int    `json:"page"`
+	Size   int    `json:"size"`
+}
+
+// InvoiceSortKeys are the keys an invoice listing can be sorted by. total,
+// cost and profit are summed over the invoice's products.
+var InvoiceSortKeys = []string{
+	"invoice_no", "date", "customer_name", "salesperson_name", "payment_type",
+	"created_at", "updated_at", "total", "cost", "profit",
+}
+
+// DefaultInvoiceSort is used when a listing does not ask for an order.
+const DefaultInvoiceSort = "-created_at"
+
+type SortField struct {
+	Key  string
+	Desc bool
+}
+
+// InvoiceCursor marks the position of an invoice in a listing sorted by
+// Sort: its sort key values, ending with its invoice number. Before selects
+// the invoices ahead of it instead of those after it.
+type InvoiceCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	Before bool     `json:"b,omitempty"`
+}
+
+// InvoiceExport is a download written out by WriteTo. The caller must Close
+// it afterwards, which removes any temporary files behind it.
+type InvoiceExport struct {
+	FileName    string
+	ContentType string
+	io.WriterTo
+	io.Closer
 }
 
+const (
+	InvoiceExportCSV    = "csv"
+	InvoiceExportNDJSON = "ndjson"
+)
+
+// ExportInvoicesRequest filters a CSV or NDJSON export. Every filter is
+// optional; UpdatedSince lets a consumer fetch only the invoices changed
+// since its last extraction.
+type ExportInvoicesRequest struct {
+	Format       string `json:"-" validate:"required,oneof=csv ndjson"`
+	DateFrom     string `json:"date_from" validate:"omitempty,datetime=2006-01-02"`
+	DateTo       string `json:"date_to" validate:"omitempty,datetime=2006-01-02"`
+	UpdatedSince string `json:"updated_since" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// CreateInvoiceRequest names the customer by CustomerID or, without one, by
+// CustomerName, matched ignoring case, punctuation and spacing, and the
+// invoice records the customer's registered name. The salesperson is named
+// the same way by SalespersonID or SalespersonName. A CustomerName or
+// SalespersonName matching none is recorded as given.
 type CreateInvoiceRequest struct {
 	InvoiceNo       string                 `json:"invoice_no" validate:"required,max=50"`
 	Date            string                 `json:"date" validate:"required,datetime=2006-01-02"`
-	CustomerName    string                 `json:"customer_name" validate:"required,min=2,max=255"`
-	SalespersonName string                 `json:"salesperson_name" validate:"required,min=2,max=255"`
+	CustomerID      string                 `json:"customer_id" validate:"required_without=CustomerName,omitempty,uuid"`
+	CustomerName    string                 `json:"customer_name" validate:"required_without=CustomerID,omitempty,min=2,max=255"`
+	SalespersonID   string                 `json:"salesperson_id" validate:"required_without=SalespersonName,omitempty,uuid"`
+	SalespersonName string                 `json:"salesperson_name" validate:"required_without=SalespersonID,omitempty,min=2,max=255"`
 	PaymentType     string                 `json:"payment_type" validate:"required,oneof=CASH CREDIT"`
 	Notes           *string                `json:"notes,omitempty" validate:"omitempty,min=5"`
+	PaymentTerms    *int                   `json:"payment_terms,omitempty" validate:"omitempty,min=0,max=365"`
 	Products        []CreateProductRequest `json:"products" validate:"required,dive"`
 }
 
 type UpdateInvoiceRequest struct {
 	Date            string                 `json:"date" validate:"required,datetime=2006-01-02"`
-	CustomerName    string                 `json:"customer_name" validate:"required,min=2,max=255"`
-	SalespersonName string                 `json:"salesperson_name" validate:"required,min=2,max=255"`
+	CustomerID      string                 `json:"customer_id" validate:"required_without=CustomerName,omitempty,uuid"`
+	CustomerName    string                 `json:"customer_name" validate:"required_without=CustomerID,omitempty,min=2,max=255"`
+	SalespersonID   string                 `json:"salesperson_id" validate:"required_without=SalespersonName,omitempty,uuid"`
+	SalespersonName string                 `json:"salesperson_name" validate:"required_without=SalespersonID,omitempty,min=2,max=255"`
 	PaymentType     string                 `json:"payment_type" validate:"required,oneof=CASH CREDIT"`
 	Notes           *string                `json:"notes,omitempty" validate:"omitempty,min=5"`
+	PaymentTerms    *int                   `json:"payment_terms,omitempty" validate:"omitempty,min=0,max=365"`
 	Products        []CreateProductRequest `json:"products" validate:"required,dive"`
 }
 
+// DefaultPaymentTerms is the number of days a CREDIT invoice is given to be
+// paid when its request does not set payment_terms, i.e. NET 30. CASH
+// invoices are due on the invoice date.
+const DefaultPaymentTerms = 30
+
+type GetInvoiceRequest struct {
+	InvoiceNo string `json:"-" validate:"required,max=50"`
+}
+
 type DeleteInvoiceRequest struct {
 	InvoiceNo string `json:"-" validate:"required"`
 }
 
+// An invoice is created as a draft, which is the only status in which it
+// can be edited or deleted. Issuing it sends it to the customer; from there
+// it is either paid or voided.
+const (
+	InvoiceStatusDraft  = "draft"
+	InvoiceStatusIssued = "issued"
+	InvoiceStatusPaid   = "paid"
+	InvoiceStatusVoid   = "void"
+)
+
+type ChangeInvoiceStatusRequest struct {
+	InvoiceNo string `json:"-" validate:"required,max=50"`
+	Status    string `json:"-" validate:"required,oneof=issued paid void"`
+}
+
+// Stable codes reported in ImportError.Code. Clients key translations on these
+// values, so existing codes must not be renamed. UNKNOWN_CUSTOMER is reported
+// as a warning when the customer is matched by name, and as an error when it
+// is matched by code.
+const (
+	ImportErrorMissingColumns         = "MISSING_COLUMNS"
+	ImportErrorRequiredField          = "REQUIRED_FIELD_MISSING"
+	ImportErrorInvalidPaymentType     = "INVALID_PAYMENT_TYPE"
+	ImportErrorInvalidDate            = "INVALID_DATE"
+	ImportErrorAmbiguousDate          = "AMBIGUOUS_DATE"
+	ImportErrorDuplicateInFile        = "DUPLICATE_INVOICE_IN_FILE"
+	ImportErrorDuplicateInvoice       = "DUPLICATE_INVOICE"
+	ImportErrorInvoiceNotEditable     = "INVOICE_NOT_EDITABLE"
+	ImportErrorInvoiceNoTooLong       = "INVOICE_NO_TOO_LONG"
+	ImportErrorInvalidCustomerName    = "INVALID_CUSTOMER_NAME"
+	ImportErrorUnknownCustomer        = "UNKNOWN_CUSTOMER"
+	ImportErrorInvalidSalespersonName = "INVALID_SALESPERSON_NAME"
+	ImportErrorNotesTooShort          = "NOTES_TOO_SHORT"
+	ImportErrorInvalidPaymentTerms    = "INVALID_PAYMENT_TERMS"
+	ImportErrorUnknownInvoiceRef      = "UNKNOWN_INVOICE_REF"
+	ImportErrorInvoiceRejected        = "INVOICE_REJECTED"
+	ImportErrorInvalidItemName        = "INVALID_ITEM_NAME"
+	ImportErrorInvalidQuantity        = "INVALID_QUANTITY"
+	ImportErrorInvalidTotalCost       = "INVALID_TOTAL_COST"
+	ImportErrorInvalidTotalPrice      = "INVALID_TOTAL_PRICE"
+	ImportErrorNoValidProducts        = "NO_VALID_PRODUCTS"
+	ImportErrorSaveFailed             = "SAVE_FAILED"
+)
+
 type ImportError struct {
+	Code      string `json:"code"`
+	Sheet     string `json:"sheet"`
+	Row       int    `json:"row"`
+	Column    string `json:"column"`
+	Value     string `json:"value"`
 	InvoiceNo string `json:"invoice_no"`
 	Message   string `json:"message"`
 }
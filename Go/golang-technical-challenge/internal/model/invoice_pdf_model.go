@@ -0,0 +1,49 @@
+package model
+
+// InvoicePDFTemplate controls the layout of rendered invoices. It is read
+// once at startup from the JSON file named by INVOICE_PDF_TEMPLATE; fields
+// missing from the file keep their default values.
+type InvoicePDFTemplate struct {
+	PageSize           string            `json:"page_size" validate:"oneof=A3 A4 A5 Letter Legal"`
+	FontFamily         string            `json:"font_family" validate:"oneof=Helvetica Arial Times Courier"`
+	AccentColor        string            `json:"accent_color" validate:"hexcolor"`
+	Title              string            `json:"title" validate:"required"`
+	Company            InvoicePDFCompany `json:"company"`
+	Currency           string            `json:"currency"`
+	ThousandsSeparator string            `json:"thousands_separator"`
+	DecimalSeparator   string            `json:"decimal_separator" validate:"required"`
+	DateFormat         string            `json:"date_format" validate:"required"`
+	Labels             InvoicePDFLabels  `json:"labels"`
+	Footer             string            `json:"footer"`
+}
+
+type InvoicePDFCompany struct {
+	Name    string   `json:"name"`
+	Address []string `json:"address"`
+	Phone   string   `json:"phone"`
+	Email   string   `json:"email"`
+	Logo    string   `json:"logo"`
+}
+
+type InvoicePDFLabels struct {
+	InvoiceNo   string `json:"invoice_no"`
+	Date        string `json:"date"`
+	Customer    string `json:"customer"`
+	Salesperson string `json:"salesperson"`
+	PaymentType string `json:"payment_type"`
+	Notes       string `json:"notes"`
+	Item        string `json:"item"`
+	Quantity    string `json:"quantity"`
+	UnitPrice   string `json:"unit_price"`
+	LineTotal   string `json:"line_total"`
+	TotalItems  string `json:"total_items"`
+	GrandTotal  string `json:"grand_total"`
+	Page        string `json:"page"`
+	Draft       string `json:"draft"`
+	Void        string `json:"void"`
+}
+
+type InvoicePDF struct {
+	FileName string
+	Content  []byte
+}
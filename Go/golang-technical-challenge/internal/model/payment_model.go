@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PaymentResponse struct {
+	ID        string          `json:"id"`
+	InvoiceNo string          `json:"invoice_no"`
+	Date      time.Time       `json:"date"`
+	Amount    decimal.Decimal `json:"amount"`
+	Method    string          `json:"method"`
+	Reference *string         `json:"reference,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type CreatePaymentRequest struct {
+	InvoiceNo string          `json:"-" validate:"required,max=50"`
+	Date      string          `json:"date" validate:"required,datetime=2006-01-02"`
+	Amount    decimal.Decimal `json:"amount" validate:"required"`
+	Method    string          `json:"method" validate:"required,oneof=cash transfer card cheque other"`
+	Reference *string         `json:"reference,omitempty" validate:"omitempty,max=100"`
+}
+
+type ListPaymentRequest struct {
+	InvoiceNo string `json:"-" validate:"required,max=50"`
+}
@@ -21,4 +21,10 @@ type ProductResponse struct {
 	TotalPrice decimal.Decimal `json:"total_price"`
 	CreatedAt  time.Time       `json:"created_at"`
 	UpdatedAt  time.Time       `json:"updated_at"`
+
+	LineRevenue decimal.Decimal `json:"line_revenue"`
+	LineCost    decimal.Decimal `json:"line_cost"`
+	LineProfit  decimal.Decimal `json:"line_profit"`
+	// MarginPercent is nil for a line without revenue.
+	MarginPercent *decimal.Decimal `json:"margin_percent"`
 }
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// How a salesperson's commission is calculated: as a percentage of the
+// profit or of the revenue of their invoices.
+const (
+	CommissionBasisProfit  = "profit"
+	CommissionBasisRevenue = "revenue"
+)
+
+// CommissionTier pays Rate percent once a salesperson's revenue for the month
+// reaches MinVolume. The highest tier reached applies to the whole month.
+type CommissionTier struct {
+	MinVolume decimal.Decimal `json:"min_volume"`
+	Rate      decimal.Decimal `json:"rate"`
+}
+
+type SalespersonResponse struct {
+	ID              string           `json:"id"`
+	Code            string           `json:"code"`
+	Name            string           `json:"name"`
+	Email           *string          `json:"email,omitempty"`
+	Phone           *string          `json:"phone,omitempty"`
+	CommissionBasis string           `json:"commission_basis"`
+	CommissionTiers []CommissionTier `json:"commission_tiers"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// CreateSalespersonRequest registers a salesperson. CommissionBasis is
+// CommissionBasisProfit when empty, and without CommissionTiers no
+// commission is paid.
+type CreateSalespersonRequest struct {
+	Code            string           `json:"code" validate:"required,max=50"`
+	Name            string           `json:"name" validate:"required,min=2,max=255"`
+	Email           *string          `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone           *string          `json:"phone,omitempty" validate:"omitempty,max=50"`
+	CommissionBasis string           `json:"commission_basis" validate:"omitempty,oneof=profit revenue"`
+	CommissionTiers []CommissionTier `json:"commission_tiers" validate:"max=20"`
+}
+
+type UpdateSalespersonRequest struct {
+	ID              string           `json:"-" validate:"required,uuid"`
+	Code            string           `json:"code" validate:"required,max=50"`
+	Name            string           `json:"name" validate:"required,min=2,max=255"`
+	Email           *string          `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone           *string          `json:"phone,omitempty" validate:"omitempty,max=50"`
+	CommissionBasis string           `json:"commission_basis" validate:"omitempty,oneof=profit revenue"`
+	CommissionTiers []CommissionTier `json:"commission_tiers" validate:"max=20"`
+}
+
+type GetSalespersonRequest struct {
+	ID string `json:"-" validate:"required,uuid"`
+}
+
+type DeleteSalespersonRequest struct {
+	ID string `json:"-" validate:"required,uuid"`
+}
+
+// SearchSalespersonRequest pages through salespersons by name. Q matches the
+// code, name or email.
+type SearchSalespersonRequest struct {
+	Q    string `json:"q" validate:"max=255"`
+	Page int    `json:"page" validate:"min=1"`
+	Size int    `json:"size" validate:"min=1,max=100"`
+}
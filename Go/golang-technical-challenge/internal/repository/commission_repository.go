@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommissionRepository struct {
+	Repository[entity.CommissionStatement]
+	Log *logrus.Logger
+}
+
+func NewCommissionRepository(log *logrus.Logger) *CommissionRepository {
+	return &CommissionRepository{
+		Repository: Repository[entity.CommissionStatement]{Log: log},
+		Log:        log,
+	}
+}
+
+// FindPeriod finds the closed period of month, the first day of a month in
+// YYYY-MM-DD format.
+func (r *CommissionRepository) FindPeriod(db *gorm.DB, period *entity.CommissionPeriod, month string) error {
+	return db.Where("month = ?", month).Take(period).Error
+}
+
+// ClosePeriod marks month closed, reporting false when it already was.
+func (r *CommissionRepository) ClosePeriod(db *gorm.DB, period *entity.CommissionPeriod) (bool, error) {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(period)
+	if result.Error != nil {
+		r.Log.WithError(result.Error).WithField("month", period.Month).Error("Failed to close commission period")
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindStatements returns the statements stored when month was closed.
+func (r *CommissionRepository) FindStatements(db *gorm.DB, month string) ([]entity.CommissionStatement, error) {
+	var statements []entity.CommissionStatement
+	if err := db.Where("month = ?", month).Order("salesperson_name, salesperson_code").Find(&statements).Error; err != nil {
+		r.Log.WithError(err).WithField("month", month).Error("Failed to find commission statements")
+		return nil, err
+	}
+	return statements, nil
+}
+
+// FindInvoices returns the issued and paid invoices that have a salesperson
+// and are dated from from up to, but not including, to, with their revenue
+// and profit summed the way GetSummary sums them.
+func (r *CommissionRepository) FindInvoices(db *gorm.DB, from, to string) ([]model.CommissionInvoice, error) {
+	var invoices []model.CommissionInvoice
+	err := db.Table("invoices").
+		Select(`invoices.invoice_no, invoices.date, invoices.salesperson_id, invoices.customer_name, invoices.status,
+			COALESCE(SUM(`+productRevenueSQL+`), 0) AS revenue,
+			COALESCE(SUM(`+productProfitSQL+`), 0) AS profit`).
+		Joins("LEFT JOIN products p ON p.invoice_no = invoices.invoice_no").
+		Where("invoices.status IN ?", []string{model.InvoiceStatusIssued, model.InvoiceStatusPaid}).
+		Where("invoices.salesperson_id IS NOT NULL").
+		Where("invoices.date >= ? AND invoices.date < ?", from, to).
+		Group("invoices.invoice_no").
+		Order("invoices.date, invoices.invoice_no").
+		Scan(&invoices).Error
+	if err != nil {
+		r.Log.WithError(err).WithFields(logrus.Fields{"from": from, "to": to}).Error("Failed to find commission invoices")
+		return nil, err
+	}
+	return invoices, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type CustomerRepository struct {
+	Repository[entity.Customer]
+	Log *logrus.Logger
+}
+
+func NewCustomerRepository(log *logrus.Logger) *CustomerRepository {
+	return &CustomerRepository{
+		Repository: Repository[entity.Customer]{Log: log},
+		Log:        log,
+	}
+}
+
+func (r *CustomerRepository) Search(db *gorm.DB, filter *model.SearchCustomerRequest, limit, offset int) ([]entity.Customer, int64, error) {
+	var customers []entity.Customer
+	var total int64
+
+	query := db.Model(&entity.Customer{})
+	if filter.Q != "" {
+		pattern := containsPattern(filter.Q)
+		query = query.Where("code ILIKE ? OR name ILIKE ? OR tax_id ILIKE ?", pattern, pattern, pattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.WithError(err).WithField("q", filter.Q).Error("Failed to count customers")
+		return nil, 0, err
+	}
+
+	if err := query.Order("name, code").
+		Limit(limit).
+		Offset(offset).
+		Find(&customers).Error; err != nil {
+		r.Log.WithError(err).
+			WithFields(logrus.Fields{
+				"q":      filter.Q,
+				"limit":  limit,
+				"offset": offset,
+			}).
+			Error("Failed to search customers")
+		return nil, 0, err
+	}
+	return customers, total, nil
+}
+
+// FindConflict returns a customer other than the one with excludeID whose
+// code is code or whose name matches name once case, punctuation and
+// spacing are ignored.
+func (r *CustomerRepository) FindConflict(db *gorm.DB, customer *entity.Customer, code, name, excludeID string) error {
+	query := db.Where("(code = ? OR name_key = ?)", code, entity.NameKey(name))
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	return query.Take(customer).Error
+}
+
+// FindByMatch returns the customers that values resolve to under match, one
+// of the model.CustomerMatch rules.
+func (r *CustomerRepository) FindByMatch(db *gorm.DB, match string, values []string) ([]entity.Customer, error) {
+	if len(values) == 0 {
+		return []entity.Customer{}, nil
+	}
+
+	column := "name_key"
+	switch match {
+	case model.CustomerMatchExact:
+		column = "name"
+	case model.CustomerMatchCode:
+		column = "code"
+	default:
+		keys := make([]string, len(values))
+		for i, value := range values {
+			keys[i] = entity.NameKey(value)
+		}
+		values = keys
+	}
+
+	var customers []entity.Customer
+	if err := db.Where(column+" IN ?", values).Find(&customers).Error; err != nil {
+		r.Log.WithError(err).WithField("match", match).Error("Failed to find customers")
+		return nil, err
+	}
+	return customers, nil
+}
+
+func (r *CustomerRepository) FindByIDs(db *gorm.DB, ids []string) ([]entity.Customer, error) {
+	if len(ids) == 0 {
+		return []entity.Customer{}, nil
+	}
+
+	var customers []entity.Customer
+	if err := db.Where("id IN ?", ids).Find(&customers).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(ids)).Error("Failed to find customers by ID")
+		return nil, err
+	}
+	return customers, nil
+}
+
+// CountInvoices counts the invoices that reference a customer.
+func (r *CustomerRepository) CountInvoices(db *gorm.DB, id string) (int64, error) {
+	var total int64
+	if err := db.Model(&entity.Invoice{}).Where("customer_id = ?", id).Count(&total).Error; err != nil {
+		r.Log.WithError(err).WithField("id", id).Error("Failed to count customer invoices")
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImportJobRepository struct {
+	Repository[entity.ImportJob]
+	Log *logrus.Logger
+}
+
+func NewImportJobRepository(log *logrus.Logger) *ImportJobRepository {
+	return &ImportJobRepository{
+		Repository: Repository[entity.ImportJob]{Log: log},
+		Log:        log,
+	}
+}
+
+func (r *ImportJobRepository) FindSummaryById(db *gorm.DB, job *entity.ImportJob, id string) error {
+	return db.Omit("file_data").
+		Where("id = ?", id).
+		Take(job).Error
+}
+
+// FindLatestByHash finds the newest import of the same file with the same
+// options that neither failed nor was undone. options is the JSON encoding
+// of the import options, compared as JSONB so key order does not matter;
+// since it says dry_run is false, dry runs never match.
+func (r *ImportJobRepository) FindLatestByHash(db *gorm.DB, job *entity.ImportJob, hash, options string) error {
+	return db.Omit("file_data").
+		Where("file_hash = ?", hash).
+		Where("options = ?::jsonb", options).
+		Where("status <> ?", entity.ImportJobStatusFailed).
+		Where("undone_at IS NULL").
+		Order("created_at DESC").
+		Take(job).Error
+}
+
+func (r *ImportJobRepository) Search(db *gorm.DB, filter *model.SearchImportJobRequest, limit, offset int) ([]entity.ImportJob, int64, error) {
+	var jobs []entity.ImportJob
+	var total int64
+
+	query := db.Model(&entity.ImportJob{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UploadedBy != "" {
+		query = query.Where("uploaded_by = ?", filter.UploadedBy)
+	}
+	if filter.FileHash != "" {
+		query = query.Where("file_hash = ?", filter.FileHash)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.WithError(err).WithField("status", filter.Status).Error("Failed to count import jobs")
+		return nil, 0, err
+	}
+
+	if err := query.Omit("file_data", "result", "created_invoice_nos").
+		Limit(limit).
+		Offset(offset).
+		Order("created_at DESC").
+		Find(&jobs).Error; err != nil {
+		r.Log.WithError(err).
+			WithFields(logrus.Fields{
+				"status": filter.Status,
+				"limit":  limit,
+				"offset": offset,
+			}).
+			Error("Failed to search import jobs")
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+// ClaimNext locks the oldest pending job, marks it as processing and loads it
+// into job. It returns gorm.ErrRecordNotFound when the queue is empty.
+func (r *ImportJobRepository) ClaimNext(db *gorm.DB, job *entity.ImportJob) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", entity.ImportJobStatusPending).
+			Order("created_at ASC").
+			Take(job).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		job.Status = entity.ImportJobStatusProcessing
+		job.StartedAt = &now
+		job.HeartbeatAt = &now
+		job.UpdatedAt = now
+
+		return tx.Model(job).Updates(map[string]any{
+			"status":       job.Status,
+			"started_at":   job.StartedAt,
+			"heartbeat_at": job.HeartbeatAt,
+			"updated_at":   job.UpdatedAt,
+		}).Error
+	})
+}
+
+// UpdateProgress records the row counts of a processing job, which also
+// counts as a heartbeat.
+func (r *ImportJobRepository) UpdateProgress(db *gorm.DB, id string, total, processed, failed int) error {
+	now := time.Now()
+	err := db.Model(&entity.ImportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"rows_total":     total,
+			"rows_processed": processed,
+			"rows_failed":    failed,
+			"heartbeat_at":   now,
+			"updated_at":     now,
+		}).Error
+	if err != nil {
+		r.Log.WithError(err).WithField("id", id).Error("Failed to update import job progress")
+	}
+	return err
+}
+
+// Heartbeat marks a processing job as still being worked on, so that
+// RequeueInterrupted leaves it alone.
+func (r *ImportJobRepository) Heartbeat(db *gorm.DB, id string) error {
+	err := db.Model(&entity.ImportJob{}).
+		Where("id = ? AND status = ?", id, entity.ImportJobStatusProcessing).
+		Update("heartbeat_at", time.Now()).Error
+	if err != nil {
+		r.Log.WithError(err).WithField("id", id).Error("Failed to record import job heartbeat")
+	}
+	return err
+}
+
+func (r *ImportJobRepository) Finish(db *gorm.DB, job *entity.ImportJob) error {
+	err := db.Model(job).
+		Select("status", "rows_total", "rows_processed", "rows_failed", "invoices_created", "invoices_updated", "invoices_skipped",
+			"created_invoice_nos", "result", "error_message", "finished_at", "updated_at").
+		Updates(job).Error
+	if err != nil {
+		r.Log.WithError(err).WithField("id", job.ID).Error("Failed to finish import job")
+	}
+	return err
+}
+
+func (r *ImportJobRepository) MarkUndone(db *gorm.DB, job *entity.ImportJob) error {
+	err := db.Model(job).
+		Select("undone_at", "updated_at").
+		Updates(job).Error
+	if err != nil {
+		r.Log.WithError(err).WithField("id", job.ID).Error("Failed to mark import job as undone")
+	}
+	return err
+}
+
+// RequeueInterrupted puts processing jobs whose last heartbeat is older than
+// staleBefore back into the queue so they are picked up again. Jobs whose
+// worker is still running keep sending heartbeats and are left alone, even
+// when they belong to another server.
+func (r *ImportJobRepository) RequeueInterrupted(db *gorm.DB, staleBefore time.Time) (int64, error) {
+	result := db.Model(&entity.ImportJob{}).
+		Where("status = ?", entity.ImportJobStatusProcessing).
+		Where("heartbeat_at IS NULL OR heartbeat_at < ?", staleBefore).
+		Updates(map[string]any{
+			"status":         entity.ImportJobStatusPending,
+			"rows_processed": 0,
+			"rows_failed":    0,
+			"started_at":     nil,
+			"heartbeat_at":   nil,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		r.Log.WithError(result.Error).Error("Failed to requeue interrupted import jobs")
+	}
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"golang-technical-challenge/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ImportProfileRepository struct {
+	Repository[entity.ImportProfile]
+	Log *logrus.Logger
+}
+
+func NewImportProfileRepository(log *logrus.Logger) *ImportProfileRepository {
+	return &ImportProfileRepository{
+		Repository: Repository[entity.ImportProfile]{Log: log},
+		Log:        log,
+	}
+}
+
+func (r *ImportProfileRepository) FindByName(db *gorm.DB, profile *entity.ImportProfile, name string) error {
+	return db.Where("name = ?", name).Take(profile).Error
+}
+
+func (r *ImportProfileRepository) FindAll(db *gorm.DB) ([]entity.ImportProfile, error) {
+	var profiles []entity.ImportProfile
+	if err := db.Order("name ASC").Find(&profiles).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find import profiles")
+		return nil, err
+	}
+	return profiles, nil
+}
@@ -1,10 +1,18 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type InvoiceRepository struct {
@@ -14,16 +22,184 @@ type InvoiceRepository struct {
 
 func NewInvoiceRepository(log *logrus.Logger) *InvoiceRepository {
 	return &InvoiceRepository{
-		Log: log,
+		Repository: Repository[entity.Invoice]{Log: log},
+		Log:        log,
 	}
 }
 
 func (r *InvoiceRepository) FindByInvoiceNo(db *gorm.DB, invoice *entity.Invoice, invoiceNo string) error {
-	return db.Preload("Products").
+	return db.Select(invoiceColumnsWithPaid).
+		Preload("Products", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at, id")
+		}).
 		Where("invoice_no = ?", invoiceNo).
 		Take(invoice).Error
 }
 
+// LockByInvoiceNo locks the row of an invoice until the end of the
+// transaction, so that concurrent payments are checked against each other.
+func (r *InvoiceRepository) LockByInvoiceNo(db *gorm.DB, invoiceNo string) error {
+	var invoice entity.Invoice
+	return db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("invoice_no").
+		Where("invoice_no = ?", invoiceNo).
+		Take(&invoice).Error
+}
+
+// UpdateStatus moves invoice to status, provided it is still in the status
+// it was read with, and reports whether it was.
+func (r *InvoiceRepository) UpdateStatus(db *gorm.DB, invoice *entity.Invoice, status string) (bool, error) {
+	now := time.Now()
+	result := db.Model(&entity.Invoice{}).
+		Where("invoice_no = ? AND status = ?", invoice.InvoiceNo, invoice.Status).
+		Updates(map[string]any{"status": status, "updated_at": now})
+	if result.Error != nil {
+		r.Log.WithError(result.Error).WithFields(logrus.Fields{
+			"invoice_no": invoice.InvoiceNo,
+			"status":     status,
+		}).Error("Failed to update invoice status")
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	invoice.Status = status
+	invoice.UpdatedAt = now
+	return true, nil
+}
+
+// Replace overwrites the invoice header and swaps its product lines for
+// invoice.Products.
+func (r *InvoiceRepository) Replace(db *gorm.DB, invoice *entity.Invoice) error {
+	if err := db.Where("invoice_no = ?", invoice.InvoiceNo).Delete(&entity.Product{}).Error; err != nil {
+		r.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to delete old products")
+		return err
+	}
+	if err := db.Save(invoice).Error; err != nil {
+		r.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to save invoice")
+		return err
+	}
+	return nil
+}
+
+// Restore puts back an invoice read by FindByInvoiceNo after Replace
+// overwrote it: the header as it was, updated_at included, and its products.
+func (r *InvoiceRepository) Restore(db *gorm.DB, invoice *entity.Invoice) error {
+	if err := db.Model(invoice).Select("*").Omit(clause.Associations).UpdateColumns(invoice).Error; err != nil {
+		r.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to restore invoice")
+		return err
+	}
+	if err := r.CreateProducts(db, invoice.Products, len(invoice.Products)); err != nil {
+		r.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to restore products")
+		return err
+	}
+	return nil
+}
+
+// FindExistingByNumbers returns the number, status, timestamps and
+// PaidAmount of the stored invoices among invoiceNos, without their products.
+func (r *InvoiceRepository) FindExistingByNumbers(db *gorm.DB, invoiceNos []string) ([]entity.Invoice, error) {
+	if len(invoiceNos) == 0 {
+		return []entity.Invoice{}, nil
+	}
+
+	var invoices []entity.Invoice
+	if err := db.Select("invoices.invoice_no, invoices.status, invoices.created_at, invoices.updated_at, "+paidAmountColumn).
+		Where("invoice_no IN ?", invoiceNos).
+		Find(&invoices).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(invoiceNos)).Error("Failed to find existing invoices")
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// Touch sets the updated_at of an invoice to now, marking it changed by
+// something stored alongside it, such as a payment.
+func (r *InvoiceRepository) Touch(db *gorm.DB, invoice *entity.Invoice) error {
+	now := time.Now()
+	if err := db.Model(&entity.Invoice{}).
+		Where("invoice_no = ?", invoice.InvoiceNo).
+		Update("updated_at", now).Error; err != nil {
+		r.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to touch invoice")
+		return err
+	}
+	invoice.UpdatedAt = now
+	return nil
+}
+
+// DeleteUnchangedSince deletes the invoices among invoiceNos that were not
+// updated after since and have no payments, and reports how many were
+// deleted.
+func (r *InvoiceRepository) DeleteUnchangedSince(db *gorm.DB, invoiceNos []string, since time.Time) (int64, error) {
+	result := db.Where("invoice_no IN ?", invoiceNos).
+		Where("updated_at <= ?", since).
+		Where("NOT EXISTS (SELECT 1 FROM payments pm WHERE pm.invoice_no = invoices.invoice_no)").
+		Delete(&entity.Invoice{})
+	if result.Error != nil {
+		r.Log.WithError(result.Error).WithField("count", len(invoiceNos)).Error("Failed to delete invoices")
+	}
+	return result.RowsAffected, result.Error
+}
+
+// CreateInBatches inserts invoices and then their products using multi-row
+// INSERTs of at most batchSize rows, keeping each statement well below the
+// bind parameter limit however many products an invoice has.
+func (r *InvoiceRepository) CreateInBatches(db *gorm.DB, invoices []entity.Invoice, batchSize int) error {
+	if err := db.Omit(clause.Associations).CreateInBatches(&invoices, batchSize).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(invoices)).Warn("Failed to create invoice batch")
+		return err
+	}
+
+	products := make([]entity.Product, 0, len(invoices))
+	for _, invoice := range invoices {
+		products = append(products, invoice.Products...)
+	}
+	if len(products) == 0 {
+		return nil
+	}
+	if err := db.CreateInBatches(&products, batchSize).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(products)).Warn("Failed to create product batch")
+		return err
+	}
+
+	// Copy the generated product IDs back onto the invoices.
+	next := 0
+	for i := range invoices {
+		next += copy(invoices[i].Products, products[next:next+len(invoices[i].Products)])
+	}
+	return nil
+}
+
+// CreateProducts inserts products of invoices that are already stored using
+// multi-row INSERTs of at most batchSize rows.
+func (r *InvoiceRepository) CreateProducts(db *gorm.DB, products []entity.Product, batchSize int) error {
+	if len(products) == 0 {
+		return nil
+	}
+	if err := db.CreateInBatches(&products, batchSize).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(products)).Warn("Failed to create product batch")
+		return err
+	}
+	return nil
+}
+
+// DeleteByNumbers deletes the invoices among invoiceNos together with their
+// products.
+func (r *InvoiceRepository) DeleteByNumbers(db *gorm.DB, invoiceNos []string) error {
+	if len(invoiceNos) == 0 {
+		return nil
+	}
+	if err := db.Where("invoice_no IN ?", invoiceNos).Delete(&entity.Product{}).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(invoiceNos)).Error("Failed to delete products")
+		return err
+	}
+	if err := db.Where("invoice_no IN ?", invoiceNos).Delete(&entity.Invoice{}).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(invoiceNos)).Error("Failed to delete invoices")
+		return err
+	}
+	return nil
+}
+
 func (r *InvoiceRepository) FindInvoicesByNumbers(db *gorm.DB, invoiceNos []string) ([]entity.Invoice, error) {
 	if len(invoiceNos) == 0 {
 		return []entity.Invoice{}, nil
@@ -43,64 +219,458 @@ func (r *InvoiceRepository) FindInvoicesByNumbers(db *gorm.DB, invoiceNos []stri
 	return invoices, nil
 }
 
-func (r *InvoiceRepository) FindInvoicesByDate(db *gorm.DB, date string, limit, offset int) ([]entity.Invoice, int64, error) {
-	var invoices []entity.Invoice
-	var total int64
-
-	query := db.Where("date = ?", date)
+// ErrInvalidCursor is returned for a cursor whose values do not fit the
+// sort it is used with.
+var ErrInvalidCursor = errors.New("invalid invoice cursor")
 
-	if err := query.Model(&entity.Invoice{}).Count(&total).Error; err != nil {
+// CountInvoices reports how many invoices match filter.
+func (r *InvoiceRepository) CountInvoices(db *gorm.DB, filter *model.InvoiceFilter) (int64, error) {
+	var total int64
+	if err := db.Model(&entity.Invoice{}).Scopes(filterInvoices(filter)).Count(&total).Error; err != nil {
 		r.Log.WithError(err).
-			WithField("date", date).
-			Error("Failed to count total invoices by date")
-		return nil, 0, err
+			WithField("filter", filter).
+			Error("Failed to count invoices")
+		return 0, err
 	}
+	return total, nil
+}
 
-	if err := query.Preload("Products").
+// FindInvoices returns a page of the invoices matching filter in the order
+// of sort. Invoices that sort equal are ordered by invoice number.
+func (r *InvoiceRepository) FindInvoices(db *gorm.DB, filter *model.InvoiceFilter, sort []model.SortField, limit, offset int) ([]entity.Invoice, error) {
+	var invoices []entity.Invoice
+	if err := db.Select(invoiceColumnsWithPaid).
+		Scopes(filterInvoices(filter), sortInvoices(sort, false)).
+		Preload("Products").
 		Limit(limit).
 		Offset(offset).
-		Order("created_at DESC").
 		Find(&invoices).Error; err != nil {
 		r.Log.WithError(err).
 			WithFields(logrus.Fields{
-				"date":   date,
+				"filter": filter,
+				"sort":   sort,
 				"limit":  limit,
 				"offset": offset,
 			}).
-			Error("Failed to find invoices by date")
-		return nil, 0, err
+			Error("Failed to find invoices")
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// FindInvoicesFrom returns up to limit of the invoices matching filter that
+// sort after cursor, or before it when cursor.Before is set, in the order of
+// sort. Seeking on the sort key values instead of skipping rows keeps deep
+// pages fast and unaffected by invoices added to earlier pages.
+func (r *InvoiceRepository) FindInvoicesFrom(db *gorm.DB, filter *model.InvoiceFilter, sort []model.SortField, cursor *model.InvoiceCursor, limit int) ([]entity.Invoice, error) {
+	fields := invoiceSortFields(sort)
+	if len(cursor.Values) != len(fields) {
+		return nil, ErrInvalidCursor
+	}
+
+	var conditions []string
+	var args []any
+	for i, field := range fields {
+		column := invoiceSortColumns[field.Key]
+		if !column.valid(cursor.Values[i]) {
+			return nil, ErrInvalidCursor
+		}
+
+		operator := ">"
+		if field.Desc != cursor.Before {
+			operator = "<"
+		}
+		var condition []string
+		for j := range i {
+			condition = append(condition, invoiceSortColumns[fields[j].Key].expr+" = ?")
+			args = append(args, cursor.Values[j])
+		}
+		condition = append(condition, column.expr+" "+operator+" ?")
+		args = append(args, cursor.Values[i])
+		conditions = append(conditions, "("+strings.Join(condition, " AND ")+")")
+	}
+
+	var invoices []entity.Invoice
+	if err := db.Select(invoiceColumnsWithPaid).
+		Scopes(filterInvoices(filter), sortInvoices(sort, cursor.Before)).
+		Where(strings.Join(conditions, " OR "), args...).
+		Preload("Products").
+		Limit(limit).
+		Find(&invoices).Error; err != nil {
+		r.Log.WithError(err).
+			WithFields(logrus.Fields{
+				"filter": filter,
+				"sort":   sort,
+				"cursor": cursor,
+				"limit":  limit,
+			}).
+			Error("Failed to find invoices from cursor")
+		return nil, err
+	}
+
+	if cursor.Before {
+		slices.Reverse(invoices)
+	}
+	return invoices, nil
+}
+
+// CursorValues returns the sort key values of invoice, whose products must
+// be loaded, for a cursor positioned on it.
+func (r *InvoiceRepository) CursorValues(invoice *entity.Invoice, sort []model.SortField) []string {
+	fields := invoiceSortFields(sort)
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = invoiceSortColumns[field.Key].value(invoice)
+	}
+	return values
+}
+
+// FindInvoicesInBatches passes the issued and paid invoices matching filter
+// to fn batchSize at a time, in invoice number order, so callers can walk a
+// large date range without loading it at once. Drafts and void invoices are
+// left out, as GetSummary leaves them out of the totals. The slice passed to
+// fn is reused between batches.
+func (r *InvoiceRepository) FindInvoicesInBatches(db *gorm.DB, filter *model.InvoiceFilter, batchSize int, fn func(invoices []entity.Invoice) error) error {
+	var invoices []entity.Invoice
+	err := db.Preload("Products", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).
+		Where("invoices.status IN ?", []string{model.InvoiceStatusIssued, model.InvoiceStatusPaid}).
+		Scopes(filterInvoices(filter)).
+		FindInBatches(&invoices, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(invoices)
+		}).Error
+	if err != nil {
+		r.Log.WithError(err).WithField("filter", filter).Error("Failed to read invoices in batches")
+		return err
+	}
+	return nil
+}
+
+// FindOpenCreditInBatches passes the CREDIT invoices dated on or before
+// asOf that still had a balance to pay on asOf to fn, batchSize at a time in
+// invoice number order, with their products. PaidAmount counts only the
+// payments received on or before asOf, so an invoice paid off later is
+// included with the balance it had then. Its current status is only used to
+// leave out drafts and void invoices. The slice passed to fn is reused
+// between batches.
+func (r *InvoiceRepository) FindOpenCreditInBatches(db *gorm.DB, asOf string, batchSize int, fn func(invoices []entity.Invoice) error) error {
+	const paidSQL = "SELECT COALESCE(SUM(pm.amount), 0) FROM payments pm WHERE pm.invoice_no = invoices.invoice_no AND pm.date <= ?"
+
+	var invoices []entity.Invoice
+	err := db.Select("invoices.*, ("+paidSQL+") AS paid_amount", asOf).
+		Preload("Products", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at, id")
+		}).
+		Where("invoices.payment_type = ?", "CREDIT").
+		Where("invoices.status IN ?", []string{model.InvoiceStatusIssued, model.InvoiceStatusPaid}).
+		Where("invoices.date <= ?", asOf).
+		Where("("+invoiceTotalSQL+") > ("+paidSQL+")", asOf).
+		FindInBatches(&invoices, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(invoices)
+		}).Error
+	if err != nil {
+		r.Log.WithError(err).WithField("as_of", asOf).Error("Failed to read open credit invoices in batches")
+		return err
+	}
+	return nil
+}
+
+// EachInvoice walks the invoices matching filter with their products, in
+// date and invoice number order, calling fn once per invoice. Invoices and
+// products are read from a single joined query whose rows are consumed as
+// they arrive, so only the current invoice is held in memory.
+func (r *InvoiceRepository) EachInvoice(db *gorm.DB, filter *model.ExportInvoicesRequest, fn func(invoice *entity.Invoice) error) error {
+	query := db.Table("invoices i").
+		Select(`i.invoice_no, i.date, i.customer_id, i.customer_name, i.salesperson_id, i.salesperson_name, i.payment_type, i.notes, i.payment_terms, i.status, i.created_at, i.updated_at,
+			(SELECT COALESCE(SUM(pm.amount), 0) FROM payments pm WHERE pm.invoice_no = i.invoice_no),
+			p.id, p.item_name, p.quantity, p.total_cost, p.total_price, p.created_at, p.updated_at`).
+		Joins("LEFT JOIN products p ON p.invoice_no = i.invoice_no")
+	if filter.DateFrom != "" {
+		query = query.Where("i.date >= ?", filter.DateFrom)
+	}
+	if filter.DateTo != "" {
+		query = query.Where("i.date <= ?", filter.DateTo)
+	}
+	if filter.UpdatedSince != "" {
+		query = query.Where("i.updated_at >= ?", filter.UpdatedSince)
+	}
+
+	rows, err := query.Order("i.date, i.invoice_no, p.created_at, p.id").Rows()
+	if err != nil {
+		r.Log.WithError(err).WithField("filter", filter).Error("Failed to query invoices for export")
+		return err
 	}
+	defer rows.Close()
 
-	return invoices, total, nil
+	var current *entity.Invoice
+	for rows.Next() {
+		var invoice entity.Invoice
+		var (
+			productID                      sql.NullString
+			itemName                       sql.NullString
+			quantity                       sql.NullInt64
+			totalCost, totalPrice          decimal.NullDecimal
+			productCreated, productUpdated sql.NullTime
+		)
+		if err := rows.Scan(
+			&invoice.InvoiceNo, &invoice.Date, &invoice.CustomerID, &invoice.CustomerName,
+			&invoice.SalespersonID, &invoice.SalespersonName,
+			&invoice.PaymentType, &invoice.Notes, &invoice.PaymentTerms, &invoice.Status, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.PaidAmount,
+			&productID, &itemName, &quantity, &totalCost, &totalPrice, &productCreated, &productUpdated,
+		); err != nil {
+			r.Log.WithError(err).Error("Failed to scan invoice export row")
+			return err
+		}
+
+		if current == nil || current.InvoiceNo != invoice.InvoiceNo {
+			if current != nil {
+				if err := fn(current); err != nil {
+					return err
+				}
+			}
+			invoice.Products = []entity.Product{}
+			current = &invoice
+		}
+		if productID.Valid {
+			current.Products = append(current.Products, entity.Product{
+				ID:         productID.String,
+				InvoiceNo:  current.InvoiceNo,
+				ItemName:   itemName.String,
+				Quantity:   int(quantity.Int64),
+				TotalCost:  totalCost.Decimal,
+				TotalPrice: totalPrice.Decimal,
+				CreatedAt:  productCreated.Time,
+				UpdatedAt:  productUpdated.Time,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		r.Log.WithError(err).Error("Failed to read invoice export rows")
+		return err
+	}
+
+	if current != nil {
+		return fn(current)
+	}
+	return nil
 }
 
-func (r *InvoiceRepository) GetSummaryByDate(db *gorm.DB, date string) (totalProfit, totalCash string, err error) {
+// productRevenueSQL and productProfitSQL are the revenue and profit of a
+// product row p over its whole quantity, as entity.Product's Revenue and
+// Profit compute them. Every report that sums profit uses them.
+const (
+	productRevenueSQL = "p.total_price * p.quantity"
+	productProfitSQL  = "(p.total_price - p.total_cost) * p.quantity"
+)
+
+// GetSummary totals the profit and cash of the invoices matching filter, so
+// it always covers the same invoices as FindInvoices, except that drafts and
+// void invoices never count towards the totals.
+func (r *InvoiceRepository) GetSummary(db *gorm.DB, filter *model.InvoiceFilter) (totalProfit, totalCash string, err error) {
 	type result struct {
 		TotalProfit string
 		TotalCash   string
 	}
 
 	var res result
-	query := `
-		SELECT 
-			COALESCE(SUM((p.total_price - p.total_cost) * p.quantity), 0)::text AS total_profit,
-			COALESCE(SUM(
+	err = db.Table("products p").
+		Select(`
+			ROUND(COALESCE(SUM(`+productProfitSQL+`), 0), 2)::text AS total_profit,
+			ROUND(COALESCE(SUM(
 				CASE 
-					WHEN i.payment_type = 'CASH' 
-					THEN (p.total_price * p.quantity) 
+					WHEN invoices.payment_type = 'CASH' 
+					THEN (`+productRevenueSQL+`) 
 					ELSE 0 
 				END
-			), 0)::text AS total_cash
-
-		FROM products p
-		JOIN invoices i ON i.invoice_no = p.invoice_no
-		WHERE i.date = ?
-	`
-
-	if err := db.Raw(query, date).Scan(&res).Error; err != nil {
-		r.Log.WithError(err).WithField("date", date).Error("Failed to calculate invoice summary")
+			), 0), 2)::text AS total_cash`).
+		Joins("JOIN invoices ON invoices.invoice_no = p.invoice_no").
+		Where("invoices.status IN ?", []string{model.InvoiceStatusIssued, model.InvoiceStatusPaid}).
+		Scopes(filterInvoices(filter)).
+		Scan(&res).Error
+	if err != nil {
+		r.Log.WithError(err).WithField("filter", filter).Error("Failed to calculate invoice summary")
 		return "0", "0", err
 	}
 
 	return res.TotalProfit, res.TotalCash, nil
 }
+
+// GetCollections totals the payments of CREDIT invoices matching filter that
+// were received on the filter's date or within its date range. The date
+// filters apply to the payments, the others to the invoices they pay.
+func (r *InvoiceRepository) GetCollections(db *gorm.DB, filter *model.InvoiceFilter) (string, error) {
+	invoiceFilter := *filter
+	invoiceFilter.Date, invoiceFilter.DateFrom, invoiceFilter.DateTo = "", "", ""
+
+	query := db.Table("payments pm").
+		Select("ROUND(COALESCE(SUM(pm.amount), 0), 2)::text").
+		Joins("JOIN invoices ON invoices.invoice_no = pm.invoice_no").
+		Where("invoices.payment_type = ?", "CREDIT").
+		Scopes(filterInvoices(&invoiceFilter))
+	if filter.Date != "" {
+		query = query.Where("pm.date = ?", filter.Date)
+	}
+	if filter.DateFrom != "" {
+		query = query.Where("pm.date >= ?", filter.DateFrom)
+	}
+	if filter.DateTo != "" {
+		query = query.Where("pm.date <= ?", filter.DateTo)
+	}
+
+	var totalCollected string
+	if err := query.Scan(&totalCollected).Error; err != nil {
+		r.Log.WithError(err).WithField("filter", filter).Error("Failed to calculate credit collections")
+		return "0", err
+	}
+	return totalCollected, nil
+}
+
+// paidAmountColumn selects the PaidAmount of an invoice.
+const paidAmountColumn = "(SELECT COALESCE(SUM(pm.amount), 0) FROM payments pm WHERE pm.invoice_no = invoices.invoice_no) AS paid_amount"
+
+// invoiceColumnsWithPaid selects an invoice with its PaidAmount.
+const invoiceColumnsWithPaid = "invoices.*, " + paidAmountColumn
+
+// filterInvoices adds the conditions of filter on the invoices table. Names
+// match case-insensitively anywhere in the value.
+func filterInvoices(filter *model.InvoiceFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Date != "" {
+			db = db.Where("invoices.date = ?", filter.Date)
+		}
+		if filter.DateFrom != "" {
+			db = db.Where("invoices.date >= ?", filter.DateFrom)
+		}
+		if filter.DateTo != "" {
+			db = db.Where("invoices.date <= ?", filter.DateTo)
+		}
+		if filter.CustomerID != "" {
+			db = db.Where("invoices.customer_id = ?", filter.CustomerID)
+		}
+		if filter.CustomerName != "" {
+			db = db.Where("invoices.customer_name ILIKE ?", containsPattern(filter.CustomerName))
+		}
+		if filter.SalespersonID != "" {
+			db = db.Where("invoices.salesperson_id = ?", filter.SalespersonID)
+		}
+		if filter.SalespersonName != "" {
+			db = db.Where("invoices.salesperson_name ILIKE ?", containsPattern(filter.SalespersonName))
+		}
+		if filter.PaymentType != "" {
+			db = db.Where("invoices.payment_type = ?", filter.PaymentType)
+		}
+		if filter.Status != "" {
+			db = db.Where("invoices.status = ?", filter.Status)
+		}
+		if filter.MinTotal != "" {
+			db = db.Where("("+invoiceTotalSQL+") >= ?", filter.MinTotal)
+		}
+		if filter.MaxTotal != "" {
+			db = db.Where("("+invoiceTotalSQL+") <= ?", filter.MaxTotal)
+		}
+		if filter.Q != "" {
+			pattern := containsPattern(filter.Q)
+			db = db.Where("invoices.notes ILIKE ? OR EXISTS (SELECT 1 FROM products q WHERE q.invoice_no = invoices.invoice_no AND q.item_name ILIKE ?)", pattern, pattern)
+		}
+		return db
+	}
+}
+
+// invoiceTotalSQL is the subtotal of the invoice in the current row.
+const invoiceTotalSQL = "SELECT COALESCE(SUM(t.total_price * t.quantity), 0) FROM products t WHERE t.invoice_no = invoices.invoice_no"
+
+type invoiceSortColumn struct {
+	expr  string
+	value func(invoice *entity.Invoice) string
+	valid func(value string) bool
+}
+
+func anyText(string) bool { return true }
+
+func validDate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
+func validTimestamp(value string) bool {
+	_, err := time.Parse(time.RFC3339Nano, value)
+	return err == nil
+}
+
+func validDecimal(value string) bool {
+	_, err := decimal.NewFromString(value)
+	return err == nil
+}
+
+// sumProducts adds up amount over the products of invoice, as the
+// subqueries of the computed sort columns do.
+func sumProducts(amount func(product *entity.Product) decimal.Decimal) func(invoice *entity.Invoice) string {
+	return func(invoice *entity.Invoice) string {
+		total := decimal.Zero
+		for i := range invoice.Products {
+			total = total.Add(amount(&invoice.Products[i]))
+		}
+		return total.String()
+	}
+}
+
+// invoiceSortColumns maps model.InvoiceSortKeys to the expressions they
+// order by, and to how a cursor records and checks their values.
+var invoiceSortColumns = map[string]invoiceSortColumn{
+	"invoice_no":       {"invoices.invoice_no", func(i *entity.Invoice) string { return i.InvoiceNo }, anyText},
+	"date":             {"invoices.date", func(i *entity.Invoice) string { return i.Date.Format("2006-01-02") }, validDate},
+	"customer_name":    {"invoices.customer_name", func(i *entity.Invoice) string { return i.CustomerName }, anyText},
+	"salesperson_name": {"invoices.salesperson_name", func(i *entity.Invoice) string { return i.SalespersonName }, anyText},
+	"payment_type":     {"invoices.payment_type", func(i *entity.Invoice) string { return i.PaymentType }, anyText},
+	"created_at":       {"invoices.created_at", func(i *entity.Invoice) string { return i.CreatedAt.Format(time.RFC3339Nano) }, validTimestamp},
+	"updated_at":       {"invoices.updated_at", func(i *entity.Invoice) string { return i.UpdatedAt.Format(time.RFC3339Nano) }, validTimestamp},
+	"total": {
+		"(" + invoiceTotalSQL + ")",
+		sumProducts((*entity.Product).Revenue),
+		validDecimal,
+	},
+	"cost": {
+		"(SELECT COALESCE(SUM(t.total_cost * t.quantity), 0) FROM products t WHERE t.invoice_no = invoices.invoice_no)",
+		sumProducts((*entity.Product).Cost),
+		validDecimal,
+	},
+	"profit": {
+		"(SELECT COALESCE(SUM((t.total_price - t.total_cost) * t.quantity), 0) FROM products t WHERE t.invoice_no = invoices.invoice_no)",
+		sumProducts((*entity.Product).Profit),
+		validDecimal,
+	},
+}
+
+// invoiceSortFields returns sort up to its invoice number key, adding one
+// if it has none, so that no two invoices sort equal.
+func invoiceSortFields(sort []model.SortField) []model.SortField {
+	for i, field := range sort {
+		if field.Key == "invoice_no" {
+			return sort[:i+1]
+		}
+	}
+	return append(sort[:len(sort):len(sort)], model.SortField{Key: "invoice_no"})
+}
+
+// sortInvoices orders by sort and then by invoice number, so a page always
+// holds the same invoices however many sort equal. reverse flips every
+// direction, for reading backwards from a cursor.
+func sortInvoices(sort []model.SortField, reverse bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, field := range invoiceSortFields(sort) {
+			column := invoiceSortColumns[field.Key].expr
+			if field.Desc != reverse {
+				column += " DESC"
+			}
+			db = db.Order(column)
+		}
+		return db
+	}
+}
+
+// containsPattern escapes the LIKE wildcards in value and wraps it in %.
+func containsPattern(value string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value) + "%"
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// invoiceTestSchema is the part of the schema the invoice listing reads,
+// written for SQLite.
+var invoiceTestSchema = []string{
+	`CREATE TABLE invoices (
+		invoice_no TEXT PRIMARY KEY,
+		date DATETIME NOT NULL,
+		customer_id TEXT,
+		customer_name TEXT NOT NULL,
+		salesperson_id TEXT,
+		salesperson_name TEXT NOT NULL,
+		payment_type TEXT NOT NULL,
+		notes TEXT,
+		payment_terms INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL DEFAULT 'draft',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE products (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		invoice_no TEXT NOT NULL REFERENCES invoices (invoice_no),
+		item_name TEXT NOT NULL,
+		quantity INTEGER NOT NULL,
+		total_cost DECIMAL(12,2) NOT NULL,
+		total_price DECIMAL(12,2) NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME
+	)`,
+	`CREATE TABLE payments (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		invoice_no TEXT NOT NULL REFERENCES invoices (invoice_no),
+		date DATETIME NOT NULL,
+		amount DECIMAL(12,2) NOT NULL,
+		method TEXT NOT NULL,
+		reference TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`,
+}
+
+// newTestInvoiceRepository stores twelve invoices whose customer and
+// salesperson names repeat, so that sorting by them leaves ties for the
+// invoice number to break.
+func newTestInvoiceRepository(t *testing.T) (*InvoiceRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, statement := range invoiceTestSchema {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+
+	customers := []string{"Gamma", "Alpha", "Beta"}
+	for i := 0; i < 12; i++ {
+		invoice := entity.Invoice{
+			InvoiceNo:       fmt.Sprintf("INV-%02d", i+1),
+			Date:            time.Date(2025, 9, 1+i%4, 0, 0, 0, 0, time.UTC),
+			CustomerName:    customers[i%3],
+			SalespersonName: fmt.Sprintf("Sales %d", i%2),
+			PaymentType:     "CASH",
+			Status:          model.InvoiceStatusIssued,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+		if err := db.Omit("Products").Create(&invoice).Error; err != nil {
+			t.Fatalf("create invoice: %v", err)
+		}
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewInvoiceRepository(log), db
+}
+
+func invoiceNumbers(invoices []entity.Invoice) []string {
+	numbers := make([]string, len(invoices))
+	for i, invoice := range invoices {
+		numbers[i] = invoice.InvoiceNo
+	}
+	return numbers
+}
+
+func TestFindInvoicesFromPagesThroughTies(t *testing.T) {
+	repo, db := newTestInvoiceRepository(t)
+	filter := &model.InvoiceFilter{}
+
+	tests := []struct {
+		name string
+		sort []model.SortField
+	}{
+		{"ascending", []model.SortField{{Key: "customer_name"}}},
+		{"descending", []model.SortField{{Key: "customer_name", Desc: true}}},
+		{"mixed", []model.SortField{{Key: "salesperson_name", Desc: true}, {Key: "customer_name"}}},
+		{"invoice number", []model.SortField{{Key: "invoice_no", Desc: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all, err := repo.FindInvoices(db, filter, tt.sort, 100, 0)
+			if err != nil {
+				t.Fatalf("FindInvoices: %v", err)
+			}
+			want := invoiceNumbers(all)
+			if len(want) != 12 {
+				t.Fatalf("found %d invoices, want 12", len(want))
+			}
+
+			// Walk forward five at a time from the first page.
+			got := want[:5]
+			last := all[4]
+			for {
+				cursor := &model.InvoiceCursor{Values: repo.CursorValues(&last, tt.sort)}
+				page, err := repo.FindInvoicesFrom(db, filter, tt.sort, cursor, 5)
+				if err != nil {
+					t.Fatalf("FindInvoicesFrom: %v", err)
+				}
+				if len(page) == 0 {
+					break
+				}
+				got = append(got, invoiceNumbers(page)...)
+				last = page[len(page)-1]
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("pages forward = %v, want %v", got, want)
+			}
+
+			// And back again from the last invoice.
+			got = want[11:]
+			first := all[11]
+			for {
+				cursor := &model.InvoiceCursor{Values: repo.CursorValues(&first, tt.sort), Before: true}
+				page, err := repo.FindInvoicesFrom(db, filter, tt.sort, cursor, 5)
+				if err != nil {
+					t.Fatalf("FindInvoicesFrom before: %v", err)
+				}
+				if len(page) == 0 {
+					break
+				}
+				got = append(invoiceNumbers(page), got...)
+				first = page[0]
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("pages backward = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFindInvoicesFromRejectsInvalidCursors(t *testing.T) {
+	repo, db := newTestInvoiceRepository(t)
+	filter := &model.InvoiceFilter{}
+
+	tests := []struct {
+		name   string
+		sort   []model.SortField
+		values []string
+	}{
+		{"no values", []model.SortField{{Key: "customer_name"}}, nil},
+		{"missing invoice number", []model.SortField{{Key: "customer_name"}}, []string{"Alpha"}},
+		{"extra value", []model.SortField{{Key: "invoice_no"}}, []string{"INV-01", "INV-02"}},
+		{"bad date", []model.SortField{{Key: "date"}}, []string{"01/09/2025", "INV-01"}},
+		{"bad timestamp", []model.SortField{{Key: "created_at"}}, []string{"2025-09-01", "INV-01"}},
+		{"bad amount", []model.SortField{{Key: "total"}}, []string{"1; DROP TABLE invoices", "INV-01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := &model.InvoiceCursor{Values: tt.values}
+			_, err := repo.FindInvoicesFrom(db, filter, tt.sort, cursor, 5)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("FindInvoicesFrom(%v) error = %v, want ErrInvalidCursor", tt.values, err)
+			}
+		})
+	}
+}
+
+func TestFindInvoicesFromBindsCursorValues(t *testing.T) {
+	repo, db := newTestInvoiceRepository(t)
+
+	// A text value is compared as a value, never spliced into the query.
+	sort := []model.SortField{{Key: "customer_name"}}
+	cursor := &model.InvoiceCursor{Values: []string{"Alpha' OR '1'='1", "INV-99"}}
+	invoices, err := repo.FindInvoicesFrom(db, &model.InvoiceFilter{}, sort, cursor, 100)
+	if err != nil {
+		t.Fatalf("FindInvoicesFrom: %v", err)
+	}
+	for _, invoice := range invoices {
+		if strings.HasPrefix(invoice.CustomerName, "Alpha") {
+			t.Errorf("found %s of %s, which sorts before the cursor", invoice.InvoiceNo, invoice.CustomerName)
+		}
+	}
+	if len(invoices) != 8 {
+		t.Errorf("found %d invoices after the cursor, want the 8 of Beta and Gamma", len(invoices))
+	}
+}
+
+func TestInvoiceSortColumnsCoverSortKeys(t *testing.T) {
+	for _, key := range model.InvoiceSortKeys {
+		if _, ok := invoiceSortColumns[key]; !ok {
+			t.Errorf("sort key %q has no column", key)
+		}
+	}
+	if len(invoiceSortColumns) != len(model.InvoiceSortKeys) {
+		t.Errorf("%d sort columns for %d sort keys", len(invoiceSortColumns), len(model.InvoiceSortKeys))
+	}
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"golang-technical-challenge/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PaymentRepository struct {
+	Repository[entity.Payment]
+	Log *logrus.Logger
+}
+
+func NewPaymentRepository(log *logrus.Logger) *PaymentRepository {
+	return &PaymentRepository{
+		Repository: Repository[entity.Payment]{Log: log},
+		Log:        log,
+	}
+}
+
+// FindByInvoiceNo returns the payments of an invoice in the order they were
+// received.
+func (r *PaymentRepository) FindByInvoiceNo(db *gorm.DB, invoiceNo string) ([]entity.Payment, error) {
+	var payments []entity.Payment
+	if err := db.Where("invoice_no = ?", invoiceNo).
+		Order("date, created_at").
+		Find(&payments).Error; err != nil {
+		r.Log.WithError(err).WithField("invoice_no", invoiceNo).Error("Failed to find payments")
+		return nil, err
+	}
+	return payments, nil
+}
@@ -18,6 +18,29 @@ func (r *Repository[T]) Create(db *gorm.DB, entity *T) error {
 	return nil
 }
 
+// InSavepoint runs fn behind a savepoint, rolling back to it when fn fails.
+func (r *Repository[T]) InSavepoint(db *gorm.DB, name string, fn func(tx *gorm.DB) error) error {
+	if err := db.SavePoint(name).Error; err != nil {
+		r.Log.WithError(err).WithField("savepoint", name).Error("Failed to create savepoint")
+		return err
+	}
+
+	if err := fn(db); err != nil {
+		r.Log.WithError(err).WithField("savepoint", name).Warn("Savepoint operation failed, rolling back to savepoint")
+		if rollbackErr := db.RollbackTo(name).Error; rollbackErr != nil {
+			r.Log.WithError(rollbackErr).WithField("savepoint", name).Error("Failed to roll back to savepoint")
+			return rollbackErr
+		}
+		return err
+	}
+
+	if err := db.Exec("RELEASE SAVEPOINT " + name).Error; err != nil {
+		r.Log.WithError(err).WithField("savepoint", name).Error("Failed to release savepoint")
+		return err
+	}
+	return nil
+}
+
 func (r *Repository[T]) Update(db *gorm.DB, entity *T) error {
 	if err := db.Save(entity).Error; err != nil {
 		r.Log.WithError(err).Error("Failed to update entity")
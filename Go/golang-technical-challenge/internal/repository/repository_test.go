@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type savepointItem struct {
+	ID   int    `gorm:"column:id;primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (savepointItem) TableName() string {
+	return "items"
+}
+
+func newTestRepository(t *testing.T) (*Repository[savepointItem], *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL CHECK (name <> ''))").Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return &Repository[savepointItem]{DB: db, Log: log}, db
+}
+
+func countItems(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&savepointItem{}).Count(&count).Error; err != nil {
+		t.Fatalf("count items: %v", err)
+	}
+	return count
+}
+
+func TestInSavepointRollsBackOnlyFailedWork(t *testing.T) {
+	repo, db := newTestRepository(t)
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	if err := tx.Create(&savepointItem{ID: 1, Name: "kept"}).Error; err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	failure := errors.New("stop")
+	err := repo.InSavepoint(tx, "step", func(tx *gorm.DB) error {
+		if err := tx.Create(&savepointItem{ID: 2, Name: "undone"}).Error; err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("InSavepoint error = %v, want %v", err, failure)
+	}
+
+	err = repo.InSavepoint(tx, "step", func(tx *gorm.DB) error {
+		return tx.Create(&savepointItem{ID: 3, Name: "released"}).Error
+	})
+	if err != nil {
+		t.Fatalf("InSavepoint after a failed one: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	var ids []int
+	db.Model(&savepointItem{}).Order("id").Pluck("id", &ids)
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Fatalf("stored ids = %v, want [1 3]", ids)
+	}
+}
+
+func TestInSavepointKeepsTransactionUsableAfterFailedInserts(t *testing.T) {
+	repo, db := newTestRepository(t)
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	create := func(item *savepointItem) error {
+		return repo.InSavepoint(tx, "item", func(tx *gorm.DB) error {
+			return tx.Create(item).Error
+		})
+	}
+	if err := create(&savepointItem{ID: 1, Name: "first"}); err != nil {
+		t.Fatalf("InSavepoint: %v", err)
+	}
+	if err := create(&savepointItem{ID: 1, Name: "duplicate"}); err == nil {
+		t.Fatal("InSavepoint with a duplicate key succeeded")
+	}
+	if err := create(&savepointItem{ID: 2, Name: ""}); err == nil {
+		t.Fatal("InSavepoint breaking a check constraint succeeded")
+	}
+	if err := create(&savepointItem{ID: 3, Name: "third"}); err != nil {
+		t.Fatalf("InSavepoint after failed ones: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if count := countItems(t, db); count != 2 {
+		t.Fatalf("stored %d items, want 2", count)
+	}
+}
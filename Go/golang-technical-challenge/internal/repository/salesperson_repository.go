@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SalespersonRepository struct {
+	Repository[entity.Salesperson]
+	Log *logrus.Logger
+}
+
+func NewSalespersonRepository(log *logrus.Logger) *SalespersonRepository {
+	return &SalespersonRepository{
+		Repository: Repository[entity.Salesperson]{Log: log},
+		Log:        log,
+	}
+}
+
+func (r *SalespersonRepository) Search(db *gorm.DB, filter *model.SearchSalespersonRequest, limit, offset int) ([]entity.Salesperson, int64, error) {
+	var salespersons []entity.Salesperson
+	var total int64
+
+	query := db.Model(&entity.Salesperson{})
+	if filter.Q != "" {
+		pattern := containsPattern(filter.Q)
+		query = query.Where("code ILIKE ? OR name ILIKE ? OR email ILIKE ?", pattern, pattern, pattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.WithError(err).WithField("q", filter.Q).Error("Failed to count salespersons")
+		return nil, 0, err
+	}
+
+	if err := query.Order("name, code").
+		Limit(limit).
+		Offset(offset).
+		Find(&salespersons).Error; err != nil {
+		r.Log.WithError(err).
+			WithFields(logrus.Fields{
+				"q":      filter.Q,
+				"limit":  limit,
+				"offset": offset,
+			}).
+			Error("Failed to search salespersons")
+		return nil, 0, err
+	}
+	return salespersons, total, nil
+}
+
+// FindConflict returns a salesperson other than the one with excludeID whose
+// code is code or whose name matches name once case, punctuation and
+// spacing are ignored.
+func (r *SalespersonRepository) FindConflict(db *gorm.DB, salesperson *entity.Salesperson, code, name, excludeID string) error {
+	query := db.Where("(code = ? OR name_key = ?)", code, entity.NameKey(name))
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	return query.Take(salesperson).Error
+}
+
+// FindByNames returns the salespersons whose names match names once case,
+// punctuation and spacing are ignored.
+func (r *SalespersonRepository) FindByNames(db *gorm.DB, names []string) ([]entity.Salesperson, error) {
+	if len(names) == 0 {
+		return []entity.Salesperson{}, nil
+	}
+
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = entity.NameKey(name)
+	}
+
+	var salespersons []entity.Salesperson
+	if err := db.Where("name_key IN ?", keys).Find(&salespersons).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(names)).Error("Failed to find salespersons by name")
+		return nil, err
+	}
+	return salespersons, nil
+}
+
+func (r *SalespersonRepository) FindByIDs(db *gorm.DB, ids []string) ([]entity.Salesperson, error) {
+	if len(ids) == 0 {
+		return []entity.Salesperson{}, nil
+	}
+
+	var salespersons []entity.Salesperson
+	if err := db.Where("id IN ?", ids).Find(&salespersons).Error; err != nil {
+		r.Log.WithError(err).WithField("count", len(ids)).Error("Failed to find salespersons by ID")
+		return nil, err
+	}
+	return salespersons, nil
+}
+
+// CountReferences counts the invoices and the closed commission statements
+// that reference a salesperson.
+func (r *SalespersonRepository) CountReferences(db *gorm.DB, id string) (invoices, statements int64, err error) {
+	if err = db.Model(&entity.Invoice{}).Where("salesperson_id = ?", id).Count(&invoices).Error; err != nil {
+		r.Log.WithError(err).WithField("id", id).Error("Failed to count salesperson invoices")
+		return 0, 0, err
+	}
+	if err = db.Model(&entity.CommissionStatement{}).Where("salesperson_id = ?", id).Count(&statements).Error; err != nil {
+		r.Log.WithError(err).WithField("id", id).Error("Failed to count salesperson commission statements")
+		return 0, 0, err
+	}
+	return invoices, statements, nil
+}
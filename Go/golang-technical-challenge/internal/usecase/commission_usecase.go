@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"golang-technical-challenge/internal/repository"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type CommissionUseCase struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	CommissionRepository  *repository.CommissionRepository
+	SalespersonRepository *repository.SalespersonRepository
+}
+
+func NewCommissionUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	commissionRepository *repository.CommissionRepository, salespersonRepository *repository.SalespersonRepository,
+) *CommissionUseCase {
+	return &CommissionUseCase{
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		CommissionRepository:  commissionRepository,
+		SalespersonRepository: salespersonRepository,
+	}
+}
+
+// GetStatements returns the commission statements of a month. An open month
+// is calculated from its invoices as they are now; a closed month returns
+// the statements stored when it was closed.
+func (c *CommissionUseCase) GetStatements(ctx context.Context, request *model.CommissionStatementRequest) (*model.CommissionReportResponse, error) {
+	month, err := c.parseMonth(request)
+	if err != nil {
+		return nil, err
+	}
+	db := c.DB.WithContext(ctx)
+	key := month.Format("2006-01-02")
+
+	period := new(entity.CommissionPeriod)
+	if err := c.CommissionRepository.FindPeriod(db, period, key); err == nil {
+		stored, err := c.CommissionRepository.FindStatements(db, key)
+		if err != nil {
+			return nil, fiber.ErrInternalServerError
+		}
+		return commissionReport(month, &period.ClosedAt, converter.CommissionStatementsToResponseList(stored)), nil
+	} else if err != gorm.ErrRecordNotFound {
+		c.Log.WithError(err).WithField("month", request.Month).Error("Failed to find commission period")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	statements, err := c.calculate(db, month)
+	if err != nil {
+		return nil, err
+	}
+	return commissionReport(month, nil, statements), nil
+}
+
+// Close calculates the statements of a month that has ended and stores them,
+// so that the month reports the same commissions from then on whatever
+// happens to its invoices or to the salespersons' rules.
+func (c *CommissionUseCase) Close(ctx context.Context, request *model.CommissionStatementRequest) (*model.CommissionReportResponse, error) {
+	month, err := c.parseMonth(request)
+	if err != nil {
+		return nil, err
+	}
+	if !month.AddDate(0, 1, 0).Before(time.Now()) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Only months that have ended can be closed")
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	// Claiming the period first makes a concurrent close of the same month
+	// wait here and then find it closed.
+	period := &entity.CommissionPeriod{Month: month, ClosedAt: time.Now()}
+	closed, err := c.CommissionRepository.ClosePeriod(tx, period)
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+	if !closed {
+		return nil, fiber.NewError(fiber.StatusConflict, "Commissions for "+request.Month+" are already closed")
+	}
+
+	statements, err := c.calculate(tx, month)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range statements {
+		stored, err := commissionStatementEntity(month, period.ClosedAt, &statement)
+		if err != nil {
+			c.Log.WithError(err).WithField("month", request.Month).Error("Failed to encode commission statement")
+			return nil, fiber.ErrInternalServerError
+		}
+		if err := c.CommissionRepository.Create(tx, stored); err != nil {
+			c.Log.WithError(err).WithFields(logrus.Fields{
+				"month":          request.Month,
+				"salesperson_id": statement.SalespersonID,
+			}).Error("Failed to store commission statement")
+			return nil, fiber.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("month", request.Month).Error("Failed to commit commission close")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return commissionReport(month, &period.ClosedAt, statements), nil
+}
+
+func (c *CommissionUseCase) parseMonth(request *model.CommissionStatementRequest) (time.Time, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid commission statement request")
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, "month must be in YYYY-MM format")
+	}
+	month, err := time.Parse("2006-01", request.Month)
+	if err != nil {
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, "month must be in YYYY-MM format")
+	}
+	return month, nil
+}
+
+// calculate builds a statement for every salesperson with issued or paid
+// invoices in month, under their current rules.
+func (c *CommissionUseCase) calculate(db *gorm.DB, month time.Time) ([]model.CommissionStatement, error) {
+	invoices, err := c.CommissionRepository.FindInvoices(db, month.Format("2006-01-02"), month.AddDate(0, 1, 0).Format("2006-01-02"))
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+
+	bySalesperson := map[string][]model.CommissionInvoice{}
+	ids := []string{}
+	for _, invoice := range invoices {
+		if _, ok := bySalesperson[invoice.SalespersonID]; !ok {
+			ids = append(ids, invoice.SalespersonID)
+		}
+		bySalesperson[invoice.SalespersonID] = append(bySalesperson[invoice.SalespersonID], invoice)
+	}
+
+	salespersons, err := c.SalespersonRepository.FindByIDs(db, ids)
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+
+	statements := make([]model.CommissionStatement, 0, len(salespersons))
+	for _, salesperson := range salespersons {
+		statement := model.CommissionStatement{
+			SalespersonID:   salesperson.ID,
+			SalespersonCode: salesperson.Code,
+			SalespersonName: salesperson.Name,
+			CommissionBasis: salesperson.CommissionBasis,
+			CommissionTiers: converter.CommissionTiers(salesperson.CommissionTiers),
+			Invoices:        bySalesperson[salesperson.ID],
+		}
+		calculateCommission(&statement)
+		statements = append(statements, statement)
+	}
+	slices.SortFunc(statements, func(a, b model.CommissionStatement) int {
+		return cmp.Or(cmp.Compare(a.SalespersonName, b.SalespersonName), cmp.Compare(a.SalespersonCode, b.SalespersonCode))
+	})
+	return statements, nil
+}
+
+// calculateCommission totals the invoices of statement and works out its
+// commission. The month's revenue is the volume that picks the tier, the
+// highest one it reaches, and that tier's rate is paid on the whole revenue
+// or profit. A month at a loss earns no commission.
+func calculateCommission(statement *model.CommissionStatement) {
+	revenue, profit := decimal.Zero, decimal.Zero
+	for _, invoice := range statement.Invoices {
+		revenue = revenue.Add(invoice.Revenue)
+		profit = profit.Add(invoice.Profit)
+	}
+
+	rate := decimal.Zero
+	for _, tier := range statement.CommissionTiers {
+		if revenue.GreaterThanOrEqual(tier.MinVolume) {
+			rate = tier.Rate
+		}
+	}
+
+	base := profit
+	if statement.CommissionBasis == model.CommissionBasisRevenue {
+		base = revenue
+	}
+
+	statement.InvoiceCount = len(statement.Invoices)
+	statement.Revenue = converter.RoundAmount(revenue)
+	statement.Profit = converter.RoundAmount(profit)
+	statement.Rate = rate
+	statement.Commission = converter.RoundAmount(decimal.Max(base.Mul(rate).Div(decimal.NewFromInt(100)), decimal.Zero))
+}
+
+func commissionReport(month time.Time, closedAt *time.Time, statements []model.CommissionStatement) *model.CommissionReportResponse {
+	total := decimal.Zero
+	for _, statement := range statements {
+		total = total.Add(statement.Commission)
+	}
+	return &model.CommissionReportResponse{
+		Month:           month.Format("2006-01"),
+		Closed:          closedAt != nil,
+		ClosedAt:        closedAt,
+		Statements:      statements,
+		TotalCommission: total,
+	}
+}
+
+func commissionStatementEntity(month, closedAt time.Time, statement *model.CommissionStatement) (*entity.CommissionStatement, error) {
+	tiers, err := json.Marshal(statement.CommissionTiers)
+	if err != nil {
+		return nil, err
+	}
+	invoices, err := json.Marshal(statement.Invoices)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.CommissionStatement{
+		Month:           month,
+		SalespersonID:   statement.SalespersonID,
+		SalespersonCode: statement.SalespersonCode,
+		SalespersonName: statement.SalespersonName,
+		CommissionBasis: statement.CommissionBasis,
+		CommissionTiers: string(tiers),
+		InvoiceCount:    statement.InvoiceCount,
+		Revenue:         statement.Revenue,
+		Profit:          statement.Profit,
+		Rate:            statement.Rate,
+		Commission:      statement.Commission,
+		Invoices:        string(invoices),
+		ClosedAt:        closedAt,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"golang-technical-challenge/internal/model"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateCommission(t *testing.T) {
+	d := decimal.RequireFromString
+	tiers := []model.CommissionTier{
+		{MinVolume: d("0"), Rate: d("1")},
+		{MinVolume: d("10000"), Rate: d("2.5")},
+		{MinVolume: d("50000"), Rate: d("4")},
+	}
+	invoices := func(amounts ...[2]string) []model.CommissionInvoice {
+		list := make([]model.CommissionInvoice, len(amounts))
+		for i, amount := range amounts {
+			list[i] = model.CommissionInvoice{Revenue: d(amount[0]), Profit: d(amount[1])}
+		}
+		return list
+	}
+
+	tests := []struct {
+		name       string
+		basis      string
+		tiers      []model.CommissionTier
+		invoices   []model.CommissionInvoice
+		rate       string
+		commission string
+	}{
+		{"lowest tier", model.CommissionBasisProfit, tiers, invoices([2]string{"9999.99", "2000"}), "1", "20"},
+		{"tier reached exactly", model.CommissionBasisProfit, tiers, invoices([2]string{"6000", "1000"}, [2]string{"4000", "1000"}), "2.5", "50"},
+		{"highest tier", model.CommissionBasisProfit, tiers, invoices([2]string{"50000", "12000"}), "4", "480"},
+		{"revenue basis", model.CommissionBasisRevenue, tiers, invoices([2]string{"12000", "3000"}), "2.5", "300"},
+		{"revenue basis rounds", model.CommissionBasisRevenue, tiers, invoices([2]string{"10000.30", "0"}), "2.5", "250.01"},
+		{"loss floors at zero", model.CommissionBasisProfit, tiers, invoices([2]string{"20000", "3000"}, [2]string{"5000", "-4000"}), "2.5", "0"},
+		{"loss on revenue basis still pays", model.CommissionBasisRevenue, tiers, invoices([2]string{"20000", "-500"}), "2.5", "500"},
+		{"below every tier", model.CommissionBasisProfit, tiers[1:], invoices([2]string{"9000", "3000"}), "0", "0"},
+		{"no tiers", model.CommissionBasisRevenue, nil, invoices([2]string{"90000", "30000"}), "0", "0"},
+		{"no invoices", model.CommissionBasisProfit, tiers, nil, "1", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := &model.CommissionStatement{
+				CommissionBasis: tt.basis,
+				CommissionTiers: tt.tiers,
+				Invoices:        tt.invoices,
+			}
+			calculateCommission(statement)
+			if statement.InvoiceCount != len(tt.invoices) {
+				t.Errorf("invoice count = %d, want %d", statement.InvoiceCount, len(tt.invoices))
+			}
+			if !statement.Rate.Equal(d(tt.rate)) || !statement.Commission.Equal(d(tt.commission)) {
+				t.Errorf("rate %s and commission %s, want %s and %s", statement.Rate, statement.Commission, tt.rate, tt.commission)
+			}
+		})
+	}
+}
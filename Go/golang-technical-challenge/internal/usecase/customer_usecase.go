@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"golang-technical-challenge/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type CustomerUseCase struct {
+	DB                 *gorm.DB
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	CustomerRepository *repository.CustomerRepository
+}
+
+func NewCustomerUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	customerRepository *repository.CustomerRepository,
+) *CustomerUseCase {
+	return &CustomerUseCase{
+		DB:                 db,
+		Log:                logger,
+		Validate:           validate,
+		CustomerRepository: customerRepository,
+	}
+}
+
+func (c *CustomerUseCase) Create(ctx context.Context, request *model.CreateCustomerRequest) (*model.CustomerResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid create customer payload")
+		return nil, fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	customer := &entity.Customer{CreatedAt: time.Now()}
+	if err := c.applyCustomer(tx, customer, request.Code, request.Name, ""); err != nil {
+		return nil, err
+	}
+	customer.ContactName = request.ContactName
+	customer.Phone = request.Phone
+	customer.Email = request.Email
+	customer.Address = request.Address
+	customer.TaxID = request.TaxID
+	customer.PaymentTerms = model.DefaultPaymentTerms
+	if request.PaymentTerms != nil {
+		customer.PaymentTerms = *request.PaymentTerms
+	}
+
+	if err := c.CustomerRepository.Create(tx, customer); err != nil {
+		c.Log.WithError(err).WithField("code", customer.Code).Error("Failed to create customer")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("code", customer.Code).Error("Failed to commit customer creation")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.CustomerToResponse(customer), nil
+}
+
+func (c *CustomerUseCase) Get(ctx context.Context, request *model.GetCustomerRequest) (*model.CustomerResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid get customer request")
+		return nil, fiber.ErrBadRequest
+	}
+
+	customer := new(entity.Customer)
+	if err := c.CustomerRepository.FindById(c.DB.WithContext(ctx), customer, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.CustomerToResponse(customer), nil
+}
+
+func (c *CustomerUseCase) Search(ctx context.Context, request *model.SearchCustomerRequest) ([]model.CustomerResponse, *model.PageMetadata, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid search customer request")
+		return nil, nil, fiber.ErrBadRequest
+	}
+
+	offset := (request.Page - 1) * request.Size
+	customers, totalItems, err := c.CustomerRepository.Search(c.DB.WithContext(ctx), request, request.Size, offset)
+	if err != nil {
+		return nil, nil, fiber.ErrInternalServerError
+	}
+
+	return converter.CustomersToResponseList(customers), &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: totalItems,
+		TotalPage: (totalItems + int64(request.Size) - 1) / int64(request.Size),
+	}, nil
+}
+
+// Update edits a customer. Its invoices keep the name they were made with.
+func (c *CustomerUseCase) Update(ctx context.Context, request *model.UpdateCustomerRequest) (*model.CustomerResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Warn("Invalid update customer payload")
+		return nil, fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	customer := new(entity.Customer)
+	if err := c.CustomerRepository.FindById(tx, customer, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.applyCustomer(tx, customer, request.Code, request.Name, customer.ID); err != nil {
+		return nil, err
+	}
+	customer.ContactName = request.ContactName
+	customer.Phone = request.Phone
+	customer.Email = request.Email
+	customer.Address = request.Address
+	customer.TaxID = request.TaxID
+	if request.PaymentTerms != nil {
+		customer.PaymentTerms = *request.PaymentTerms
+	}
+	customer.UpdatedAt = time.Now()
+
+	if err := c.CustomerRepository.Update(tx, customer); err != nil {
+		c.Log.WithError(err).WithField("id", customer.ID).Error("Failed to update customer")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("id", customer.ID).Error("Failed to commit customer update")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.CustomerToResponse(customer), nil
+}
+
+// Delete removes a customer that no invoice references.
+func (c *CustomerUseCase) Delete(ctx context.Context, request *model.DeleteCustomerRequest) error {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid delete customer request")
+		return fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	customer := new(entity.Customer)
+	if err := c.CustomerRepository.FindById(tx, customer, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fiber.ErrNotFound
+		}
+		return fiber.ErrInternalServerError
+	}
+
+	invoices, err := c.CustomerRepository.CountInvoices(tx, customer.ID)
+	if err != nil {
+		return fiber.ErrInternalServerError
+	}
+	if invoices > 0 {
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Customer has %d invoices and cannot be deleted", invoices))
+	}
+
+	if err := c.CustomerRepository.Delete(tx, customer); err != nil {
+		c.Log.WithError(err).WithField("id", customer.ID).Error("Failed to delete customer")
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("id", customer.ID).Error("Failed to commit customer deletion")
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// applyCustomer sets the code and name of customer after checking that no
+// other customer has the code or, ignoring case, punctuation and spacing,
+// the name.
+func (c *CustomerUseCase) applyCustomer(tx *gorm.DB, customer *entity.Customer, code, name, id string) error {
+	code, name = strings.TrimSpace(code), strings.TrimSpace(name)
+	if code == "" || entity.NameKey(name) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "code must not be blank and name must contain letters or digits")
+	}
+
+	existing := new(entity.Customer)
+	if err := c.CustomerRepository.FindConflict(tx, existing, code, name, id); err == nil {
+		if existing.Code == code {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Customer code %s is already used by %s", code, existing.Name))
+		}
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Customer %s (%s) already has this name", existing.Name, existing.Code))
+	} else if err != gorm.ErrRecordNotFound {
+		c.Log.WithError(err).WithField("code", code).Error("Failed to check existing customers")
+		return fiber.ErrInternalServerError
+	}
+
+	customer.Code = code
+	customer.Name = name
+	return nil
+}
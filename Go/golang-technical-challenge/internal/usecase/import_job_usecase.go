@@ -0,0 +1,705 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"golang-technical-challenge/internal/repository"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	importProgressInterval = time.Second
+	// importHeartbeatInterval is how often a processing job is marked as
+	// alive. The lease timeout given to RequeueInterrupted must be well above
+	// it.
+	importHeartbeatInterval = 30 * time.Second
+)
+
+type ImportJobUseCase struct {
+	DB                      *gorm.DB
+	Log                     *logrus.Logger
+	Validate                *validator.Validate
+	ImportJobRepository     *repository.ImportJobRepository
+	ImportProfileRepository *repository.ImportProfileRepository
+	InvoiceUseCase          *InvoiceUseCase
+}
+
+func NewImportJobUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	importJobRepository *repository.ImportJobRepository, importProfileRepository *repository.ImportProfileRepository,
+	invoiceUseCase *InvoiceUseCase,
+) *ImportJobUseCase {
+	return &ImportJobUseCase{
+		DB:                      db,
+		Log:                     logger,
+		Validate:                validate,
+		ImportJobRepository:     importJobRepository,
+		ImportProfileRepository: importProfileRepository,
+		InvoiceUseCase:          invoiceUseCase,
+	}
+}
+
+// Create queues an import. Unless upload.Force is set, uploading a file that
+// was already imported with the same options returns the earlier job, marked
+// as a duplicate, instead of queueing it again. Options are normalized first,
+// so leaving customer_match out matches an import that set it to normalized.
+// Dry runs are neither checked nor matched.
+func (c *ImportJobUseCase) Create(ctx context.Context, upload *model.ImportUpload, options *model.ImportOptions) (*model.ImportJobResponse, error) {
+	if err := c.Validate.Struct(options); err != nil {
+		c.Log.WithError(err).Warn("Invalid import options")
+		return nil, fiber.NewError(fiber.StatusBadRequest, validationMessage("Invalid import options", err))
+	}
+	if err := c.Validate.Struct(upload); err != nil {
+		c.Log.WithError(err).Warn("Invalid import upload")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "uploaded_by must be at most 255 characters")
+	}
+
+	if options.ProfileName != "" {
+		profile := new(entity.ImportProfile)
+		if err := c.ImportProfileRepository.FindByName(c.DB.WithContext(ctx), profile, options.ProfileName); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.Log.WithField("profile", options.ProfileName).Warn("Import profile not found")
+				return nil, fiber.NewError(fiber.StatusBadRequest, "Unknown import profile")
+			}
+			c.Log.WithError(err).WithField("profile", options.ProfileName).Error("Failed to fetch import profile")
+			return nil, fiber.ErrInternalServerError
+		}
+		options.Profile = converter.ImportProfileToResponse(profile)
+	}
+
+	fileName, data, format, err := c.readUpload(upload)
+	if err != nil {
+		return nil, err
+	}
+	options.Format = format
+
+	if options.CustomerMatch == "" {
+		options.CustomerMatch = model.CustomerMatchNormalized
+	}
+
+	sum := sha256.Sum256(data)
+	fileHash := hex.EncodeToString(sum[:])
+
+	encodedOptions, err := json.Marshal(options)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to encode import options")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if !upload.Force && !options.DryRun {
+		earlier := new(entity.ImportJob)
+		err := c.ImportJobRepository.FindLatestByHash(c.DB.WithContext(ctx), earlier, fileHash, string(encodedOptions))
+		if err == nil {
+			c.Log.WithFields(logrus.Fields{"file_hash": fileHash, "id": earlier.ID}).Info("File was already imported")
+			response := converter.ImportJobToResponse(earlier)
+			response.Duplicate = true
+			return response, nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			c.Log.WithError(err).WithField("file_hash", fileHash).Error("Failed to look up earlier imports")
+			return nil, fiber.ErrInternalServerError
+		}
+	}
+
+	var uploadedBy *string
+	if upload.UploadedBy != "" {
+		uploadedBy = &upload.UploadedBy
+	}
+
+	job := &entity.ImportJob{
+		FileName:   fileName,
+		FileData:   data,
+		FileHash:   fileHash,
+		UploadedBy: uploadedBy,
+		Status:     entity.ImportJobStatusPending,
+		Options:    string(encodedOptions),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	if err := c.ImportJobRepository.Create(c.DB.WithContext(ctx), job); err != nil {
+		c.Log.WithError(err).WithField("file_name", fileName).Error("Failed to queue import job")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ImportJobToResponse(job), nil
+}
+
+// readUpload resolves the upload into the bytes stored on the job. A pair of
+// CSV files is packed into a zip archive so every CSV import is stored, and
+// later re-read, the same way as an uploaded archive. The archive is built
+// the same way every time so re-uploads of the pair hash the same.
+func (c *ImportJobUseCase) readUpload(upload *model.ImportUpload) (string, []byte, string, error) {
+	if upload.File != nil {
+		data, err := c.readFormFile(upload.File)
+		if err != nil {
+			return "", nil, "", err
+		}
+
+		switch detectUploadFormat(upload.File) {
+		case model.ImportFormatXLSX:
+			return upload.File.Filename, data, model.ImportFormatXLSX, nil
+		case "zip":
+			if _, err := loadCSVSource(bytes.NewReader(data), int64(len(data))); err != nil {
+				c.Log.WithError(err).WithField("file_name", upload.File.Filename).Warn("Invalid CSV archive")
+				return "", nil, "", fiber.NewError(fiber.StatusBadRequest, "Zip archive must contain an invoices CSV file and a products CSV file")
+			}
+			return upload.File.Filename, data, model.ImportFormatCSV, nil
+		case model.ImportFormatCSV:
+			return "", nil, "", fiber.NewError(fiber.StatusBadRequest, "CSV imports need both an invoices file and a products file")
+		}
+		return "", nil, "", fiber.NewError(fiber.StatusBadRequest, "Unsupported file type, upload an XLSX workbook, a zip archive or CSV files")
+	}
+
+	if upload.Invoices == nil || upload.Products == nil {
+		return "", nil, "", fiber.NewError(fiber.StatusBadRequest, "File is required")
+	}
+	if detectUploadFormat(upload.Invoices) != model.ImportFormatCSV || detectUploadFormat(upload.Products) != model.ImportFormatCSV {
+		return "", nil, "", fiber.NewError(fiber.StatusBadRequest, "Invoices and products must be CSV or TSV files")
+	}
+
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+	for _, file := range []struct {
+		name   string
+		header *multipart.FileHeader
+	}{{"invoices", upload.Invoices}, {"products", upload.Products}} {
+		name, header := file.name, file.header
+		data, err := c.readFormFile(header)
+		if err != nil {
+			return "", nil, "", err
+		}
+		w, err := archive.Create(name + strings.ToLower(filepath.Ext(header.Filename)))
+		if err != nil {
+			c.Log.WithError(err).Error("Failed to pack CSV files")
+			return "", nil, "", fiber.ErrInternalServerError
+		}
+		if _, err := w.Write(data); err != nil {
+			c.Log.WithError(err).Error("Failed to pack CSV files")
+			return "", nil, "", fiber.ErrInternalServerError
+		}
+	}
+	if err := archive.Close(); err != nil {
+		c.Log.WithError(err).Error("Failed to pack CSV files")
+		return "", nil, "", fiber.ErrInternalServerError
+	}
+
+	return upload.Invoices.Filename + ", " + upload.Products.Filename, buffer.Bytes(), model.ImportFormatCSV, nil
+}
+
+func (c *ImportJobUseCase) readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	f, err := header.Open()
+	if err != nil {
+		c.Log.WithError(err).WithField("file_name", header.Filename).Error("Failed to open uploaded file")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Unable to read uploaded file")
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.Log.WithError(err).WithField("file_name", header.Filename).Error("Failed to read uploaded file")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Unable to read uploaded file")
+	}
+	return data, nil
+}
+
+// detectUploadFormat looks at the declared content type first and falls back
+// to the file extension, since browsers often send CSV as octet-stream.
+func detectUploadFormat(header *multipart.FileHeader) string {
+	contentType, _, _ := strings.Cut(header.Header.Get("Content-Type"), ";")
+	switch strings.TrimSpace(strings.ToLower(contentType)) {
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return model.ImportFormatXLSX
+	case "text/csv", "application/csv", "text/tab-separated-values":
+		return model.ImportFormatCSV
+	case "application/zip", "application/x-zip-compressed":
+		return "zip"
+	}
+
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".xlsx":
+		return model.ImportFormatXLSX
+	case ".csv", ".tsv", ".txt":
+		return model.ImportFormatCSV
+	case ".zip":
+		return "zip"
+	}
+	return ""
+}
+
+func (c *ImportJobUseCase) Get(ctx context.Context, request *model.GetImportJobRequest) (*model.ImportJobResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Warn("Invalid get import job request")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid import job ID")
+	}
+
+	job := new(entity.ImportJob)
+	if err := c.ImportJobRepository.FindSummaryById(c.DB.WithContext(ctx), job, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Log.WithField("id", request.ID).Warn("Import job not found")
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to fetch import job")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ImportJobToResponse(job), nil
+}
+
+func (c *ImportJobUseCase) Search(ctx context.Context, request *model.SearchImportJobRequest) ([]model.ImportJobResponse, *model.PageMetadata, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid search import job request")
+		return nil, nil, fiber.ErrBadRequest
+	}
+
+	offset := (request.Page - 1) * request.Size
+	jobs, totalItems, err := c.ImportJobRepository.Search(c.DB.WithContext(ctx), request, request.Size, offset)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to search import jobs")
+		return nil, nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ImportJobsToResponseList(jobs), &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: totalItems,
+		TotalPage: (totalItems + int64(request.Size) - 1) / int64(request.Size),
+	}, nil
+}
+
+// ErrorWorkbook returns the uploaded workbook annotated with the errors of a
+// finished import: failing rows are highlighted, an "errors" column explains
+// each problem and an "import errors" sheet summarises all of them.
+func (c *ImportJobUseCase) ErrorWorkbook(ctx context.Context, request *model.GetImportJobRequest) (*model.ImportErrorWorkbook, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Warn("Invalid import error workbook request")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid import job ID")
+	}
+
+	job := new(entity.ImportJob)
+	if err := c.ImportJobRepository.FindById(c.DB.WithContext(ctx), job, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to fetch import job")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := converter.ImportJobToResponse(job)
+	if response.Status != entity.ImportJobStatusCompleted || response.Result == nil {
+		return nil, fiber.NewError(fiber.StatusConflict, "Import job has not completed yet")
+	}
+	if len(response.Result.Errors) == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Import job has no errors")
+	}
+
+	headerRow := 1
+	if response.Options.Profile != nil {
+		headerRow = response.Options.Profile.HeaderRow
+	}
+
+	content, err := annotateImportWorkbook(response.Options.Format, job.FileData, headerRow, response.Result.Errors)
+	if err != nil {
+		c.Log.WithError(err).WithField("id", job.ID).Error("Failed to build import error workbook")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.ImportErrorWorkbook{
+		FileName: importErrorWorkbookName(job.FileName),
+		Content:  content,
+	}, nil
+}
+
+// importErrorWorkbookName derives the download name from the first uploaded
+// file, e.g. "invoices.csv, products.csv" becomes "invoices-errors.xlsx".
+func importErrorWorkbookName(fileName string) string {
+	first, _, _ := strings.Cut(fileName, ", ")
+	return strings.TrimSuffix(first, filepath.Ext(first)) + "-errors.xlsx"
+}
+
+func annotateImportWorkbook(format string, data []byte, headerRow int, importErrors []model.ImportError) ([]byte, error) {
+	xlsx, err := openImportWorkbook(format, data)
+	if err != nil {
+		return nil, err
+	}
+	defer xlsx.Close()
+
+	// highlight keeps each cell's own style (number and date formats) and only
+	// swaps the fill, so the annotated file still reads like the original.
+	// Strong highlighting marks the offending cell itself.
+	type styleKey struct {
+		original int
+		strong   bool
+	}
+	highlighted := map[styleKey]int{}
+	highlight := func(sheet, cell string, strong bool) error {
+		original, err := xlsx.GetCellStyle(sheet, cell)
+		if err != nil {
+			return err
+		}
+		key := styleKey{original: original, strong: strong}
+		styleID, ok := highlighted[key]
+		if !ok {
+			style, err := xlsx.GetStyle(original)
+			if err != nil {
+				return err
+			}
+			style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FDE2E1"}}
+			if strong {
+				style.Fill.Color = []string{"F8A5A0"}
+				if style.Font == nil {
+					style.Font = &excelize.Font{}
+				}
+				style.Font.Bold = true
+				style.Font.Color = "9C0006"
+			}
+			if styleID, err = xlsx.NewStyle(style); err != nil {
+				return err
+			}
+			highlighted[key] = styleID
+		}
+		return xlsx.SetCellStyle(sheet, cell, cell, styleID)
+	}
+
+	headerStyle, err := xlsx.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := map[string]map[int][]string{}
+	for _, importErr := range importErrors {
+		if importErr.Sheet == "" || importErr.Row == 0 {
+			continue
+		}
+		if messages[importErr.Sheet] == nil {
+			messages[importErr.Sheet] = map[int][]string{}
+		}
+		messages[importErr.Sheet][importErr.Row] = append(messages[importErr.Sheet][importErr.Row],
+			fmt.Sprintf("%s%d: %s (%s)", importErr.Column, importErr.Row, importErr.Message, importErr.Code))
+	}
+
+	for sheet, rows := range messages {
+		if index, err := xlsx.GetSheetIndex(sheet); err != nil || index < 0 {
+			continue
+		}
+
+		header, err := xlsx.GetRows(sheet)
+		if err != nil {
+			return nil, err
+		}
+		lastColumn := 0
+		for _, row := range header {
+			lastColumn = max(lastColumn, len(row))
+		}
+		errorColumn, _ := excelize.ColumnNumberToName(lastColumn + 1)
+
+		headerCell := fmt.Sprintf("%s%d", errorColumn, headerRow)
+		if err := xlsx.SetCellValue(sheet, headerCell, "errors"); err != nil {
+			return nil, err
+		}
+		if err := xlsx.SetCellStyle(sheet, headerCell, headerCell, headerStyle); err != nil {
+			return nil, err
+		}
+		if err := xlsx.SetColWidth(sheet, errorColumn, errorColumn, 60); err != nil {
+			return nil, err
+		}
+
+		for row, rowMessages := range rows {
+			for column := 1; column <= lastColumn; column++ {
+				cell, _ := excelize.CoordinatesToCellName(column, row)
+				if err := highlight(sheet, cell, false); err != nil {
+					return nil, err
+				}
+			}
+			cell := fmt.Sprintf("%s%d", errorColumn, row)
+			if err := xlsx.SetCellValue(sheet, cell, strings.Join(rowMessages, "; ")); err != nil {
+				return nil, err
+			}
+			if err := highlight(sheet, cell, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, importErr := range importErrors {
+		if importErr.Column == "" || importErr.Row == 0 {
+			continue
+		}
+		if index, err := xlsx.GetSheetIndex(importErr.Sheet); err != nil || index < 0 {
+			continue
+		}
+		if err := highlight(importErr.Sheet, fmt.Sprintf("%s%d", importErr.Column, importErr.Row), true); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeImportErrorSummary(xlsx, importErrors, headerStyle); err != nil {
+		return nil, err
+	}
+
+	buffer, err := xlsx.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func writeImportErrorSummary(xlsx *excelize.File, importErrors []model.ImportError, headerStyle int) error {
+	const summarySheet = "import errors"
+
+	if _, err := xlsx.NewSheet(summarySheet); err != nil {
+		return err
+	}
+
+	if err := xlsx.SetSheetRow(summarySheet, "A1", &[]any{"total errors", len(importErrors)}); err != nil {
+		return err
+	}
+	header := []any{"sheet", "row", "column", "code", "invoice no", "value", "message"}
+	if err := xlsx.SetSheetRow(summarySheet, "A3", &header); err != nil {
+		return err
+	}
+	if err := xlsx.SetCellStyle(summarySheet, "A3", "G3", headerStyle); err != nil {
+		return err
+	}
+
+	for i, importErr := range importErrors {
+		row := []any{importErr.Sheet, importErr.Row, importErr.Column, importErr.Code, importErr.InvoiceNo, importErr.Value, importErr.Message}
+		if err := xlsx.SetSheetRow(summarySheet, fmt.Sprintf("A%d", i+4), &row); err != nil {
+			return err
+		}
+	}
+
+	if err := xlsx.SetColWidth(summarySheet, "A", "F", 16); err != nil {
+		return err
+	}
+	if err := xlsx.SetColWidth(summarySheet, "G", "G", 60); err != nil {
+		return err
+	}
+	return xlsx.AutoFilter(summarySheet, fmt.Sprintf("A3:G%d", len(importErrors)+3), nil)
+}
+
+// Undo deletes the invoices created by a completed import. It refuses when any
+// of them was updated after the import finished; invoices that were already
+// deleted are ignored. Invoices the import replaced are left as they are.
+func (c *ImportJobUseCase) Undo(ctx context.Context, request *model.GetImportJobRequest) (*model.ImportJobResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Warn("Invalid undo import request")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid import job ID")
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	job := new(entity.ImportJob)
+	if err := c.ImportJobRepository.FindSummaryById(tx, job, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("id", request.ID).Error("Failed to fetch import job")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := converter.ImportJobToResponse(job)
+	switch {
+	case job.UndoneAt != nil:
+		return nil, fiber.NewError(fiber.StatusConflict, "Import has already been undone")
+	case job.Status != entity.ImportJobStatusCompleted || job.FinishedAt == nil:
+		return nil, fiber.NewError(fiber.StatusConflict, "Only completed imports can be undone")
+	case response.Options.DryRun:
+		return nil, fiber.NewError(fiber.StatusConflict, "Dry runs did not create any invoices")
+	}
+
+	invoiceNos := response.CreatedInvoiceNos
+	present := 0
+	var changed []string
+	for start := 0; start < len(invoiceNos); start += importChunkSize {
+		chunk := invoiceNos[start:min(start+importChunkSize, len(invoiceNos))]
+		invoices, err := c.InvoiceUseCase.InvoiceRepository.FindExistingByNumbers(tx, chunk)
+		if err != nil {
+			return nil, fiber.ErrInternalServerError
+		}
+		present += len(invoices)
+		for _, invoice := range invoices {
+			if invoice.UpdatedAt.After(*job.FinishedAt) {
+				changed = append(changed, invoice.InvoiceNo)
+			}
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		c.Log.WithFields(logrus.Fields{"id": job.ID, "changed": len(changed)}).Warn("Import cannot be undone")
+		return nil, fiber.NewError(fiber.StatusConflict,
+			fmt.Sprintf("Import cannot be undone, invoices changed since: %s", strings.Join(changed, ", ")))
+	}
+
+	var deleted int64
+	for start := 0; start < len(invoiceNos); start += importChunkSize {
+		chunk := invoiceNos[start:min(start+importChunkSize, len(invoiceNos))]
+		count, err := c.InvoiceUseCase.InvoiceRepository.DeleteUnchangedSince(tx, chunk, *job.FinishedAt)
+		if err != nil {
+			return nil, fiber.ErrInternalServerError
+		}
+		deleted += count
+	}
+	if deleted != int64(present) {
+		c.Log.WithFields(logrus.Fields{"id": job.ID, "expected": present, "deleted": deleted}).Warn("Invoices changed while undoing import")
+		return nil, fiber.NewError(fiber.StatusConflict, "Invoices changed while undoing the import, try again")
+	}
+
+	now := time.Now()
+	job.UndoneAt = &now
+	job.UpdatedAt = now
+	if err := c.ImportJobRepository.MarkUndone(tx, job); err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("id", job.ID).Error("Failed to commit import undo")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	c.Log.WithFields(logrus.Fields{"id": job.ID, "deleted": deleted}).Info("Import undone")
+	return converter.ImportJobToResponse(job), nil
+}
+
+// RequeueInterrupted requeues the processing jobs that have not sent a
+// heartbeat for leaseTimeout, whichever server was running them.
+func (c *ImportJobUseCase) RequeueInterrupted(ctx context.Context, leaseTimeout time.Duration) error {
+	count, err := c.ImportJobRepository.RequeueInterrupted(c.DB.WithContext(ctx), time.Now().Add(-leaseTimeout))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		c.Log.WithField("count", count).Info("Requeued interrupted import jobs")
+	}
+	return nil
+}
+
+// ProcessNext claims the oldest pending job and runs the import for it. It
+// reports false when there was nothing to process.
+func (c *ImportJobUseCase) ProcessNext(ctx context.Context) (bool, error) {
+	job := new(entity.ImportJob)
+	if err := c.ImportJobRepository.ClaimNext(c.DB.WithContext(ctx), job); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		c.Log.WithError(err).Error("Failed to claim import job")
+		return false, err
+	}
+
+	c.process(ctx, job)
+	return true, nil
+}
+
+func (c *ImportJobUseCase) process(ctx context.Context, job *entity.ImportJob) {
+	log := c.Log.WithFields(logrus.Fields{"id": job.ID, "file_name": job.FileName})
+	log.Info("Processing import job")
+
+	stopHeartbeat := c.heartbeat(ctx, job, log)
+
+	lastReport := time.Now()
+	progress := func(total, processed, failed int) {
+		job.RowsTotal, job.RowsProcessed, job.RowsFailed = total, processed, failed
+		if time.Since(lastReport) < importProgressInterval {
+			return
+		}
+		lastReport = time.Now()
+		if err := c.ImportJobRepository.UpdateProgress(c.DB.WithContext(ctx), job.ID, total, processed, failed); err != nil {
+			log.WithError(err).Warn("Failed to report import job progress")
+		}
+	}
+
+	var payload []byte
+	result, err := c.runImport(ctx, job, progress)
+	if err == nil {
+		payload, err = json.Marshal(result)
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.UpdatedAt = now
+
+	if err != nil {
+		log.WithError(err).Error("Import job failed")
+		message := err.Error()
+		job.Status = entity.ImportJobStatusFailed
+		job.ErrorMessage = &message
+	} else {
+		encoded := string(payload)
+		job.Status = entity.ImportJobStatusCompleted
+		job.Result = &encoded
+		job.InvoicesCreated, job.InvoicesUpdated, job.InvoicesSkipped = result.Created, result.Updated, result.Skipped
+		if createdNos, err := json.Marshal(result.CreatedInvoiceNos); err == nil && result.CreatedInvoiceNos != nil {
+			job.CreatedInvoiceNos = string(createdNos)
+		}
+		log.WithField("error_count", len(result.Errors)).Info("Import job completed")
+	}
+
+	stopHeartbeat()
+	if err := c.ImportJobRepository.Finish(c.DB.WithContext(ctx), job); err != nil {
+		log.WithError(err).WithField("status", job.Status).Error("Failed to record import job result, it will be requeued once its lease expires")
+	}
+}
+
+// heartbeat marks job as alive every importHeartbeatInterval until the
+// returned function is called, so that a long import is not mistaken for
+// one left behind by a stopped server.
+func (c *ImportJobUseCase) heartbeat(ctx context.Context, job *entity.ImportJob, log *logrus.Entry) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(importHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.ImportJobRepository.Heartbeat(c.DB.WithContext(ctx), job.ID); err != nil {
+					log.WithError(err).Warn("Failed to send import job heartbeat")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (c *ImportJobUseCase) runImport(ctx context.Context, job *entity.ImportJob, progress ImportProgressFunc) (result *model.ImportResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("import panicked: %v", r)
+		}
+	}()
+
+	options := model.ImportOptions{Mode: model.ImportModeBestEffort, OnConflict: model.ImportConflictError}
+	if err := json.Unmarshal([]byte(job.Options), &options); err != nil {
+		return nil, fmt.Errorf("invalid import options: %w", err)
+	}
+
+	return c.InvoiceUseCase.ImportInvoices(ctx, bytes.NewReader(job.FileData), int64(len(job.FileData)), options, progress)
+}
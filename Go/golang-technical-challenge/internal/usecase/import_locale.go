@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+type dateOrder int
+
+const (
+	dateOrderUnknown dateOrder = iota
+	dateOrderDMY
+	dateOrderMDY
+)
+
+// importLocale decides how numbers and dates written as text are read. The
+// zero value is used when an import has no locale: numbers must be written
+// without thousands separators and with "." as the decimal point, and a date
+// like 03/04/2025 is reported as ambiguous instead of guessed.
+type importLocale struct {
+	thousands string
+	decimal   string
+	order     dateOrder
+	number    *regexp.Regexp
+}
+
+var importLocales = map[string]importLocale{
+	"":      {decimal: "."},
+	"en-US": {thousands: ",", decimal: ".", order: dateOrderMDY},
+	"en-GB": {thousands: ",", decimal: ".", order: dateOrderDMY},
+	"id-ID": {thousands: ".", decimal: ",", order: dateOrderDMY},
+	"de-DE": {thousands: ".", decimal: ",", order: dateOrderDMY},
+	"fr-FR": {thousands: " ", decimal: ",", order: dateOrderDMY},
+}
+
+func importLocaleFor(name string) (importLocale, error) {
+	locale, ok := importLocales[name]
+	if !ok {
+		return importLocale{}, fmt.Errorf("unsupported import locale: %s", name)
+	}
+	locale.number = numberPattern(locale.thousands, locale.decimal)
+	return locale, nil
+}
+
+var (
+	// typedNumber matches numeric cell values as stored in the workbook XML,
+	// which do not depend on the locale the workbook was written in.
+	typedNumber    = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][-+]?\d+)?$`)
+	currencyPrefix = regexp.MustCompile(`^[\p{L}\p{Sc}]+\.?\s*`)
+	currencySuffix = regexp.MustCompile(`\s*[\p{L}\p{Sc}]+$`)
+	numericDate    = regexp.MustCompile(`^(\d{1,2})([/.-])(\d{1,2})([/.-])(\d{4}|\d{2})$`)
+	// serialDate matches an Excel date serial written as text, as in CSV
+	// files and in cells formatted as text. Five digits reach the year 2173.
+	serialDate = regexp.MustCompile(`^\d{1,5}(\.\d+)?$`)
+)
+
+// Layouts that read the same in every locale.
+var unambiguousDateLayouts = []string{
+	"2006-01-02", "2006/01/02", "2006-01-02 15:04:05", time.RFC3339,
+	"2 Jan 2006", "2 January 2006", "2-Jan-2006", "2-Jan-06",
+	"Jan 2, 2006", "January 2, 2006",
+}
+
+// typedCellAt returns the value of column in row and whether it is a number
+// stored as such in the workbook. Other cells, and every CSV cell, are text
+// that has to be read with the import locale.
+func typedCellAt(row importRow, column string) (string, bool) {
+	if row.typed != nil {
+		if value := strings.TrimSpace(cellAt(row.typed, column)); typedNumber.MatchString(value) {
+			return value, true
+		}
+	}
+	return cellAt(row.cells, column), false
+}
+
+// parseNumber reads an amount such as "25.000.000,00" or "Rp 1.500.000" for
+// id-ID. Currency symbols and codes around the number are ignored. Thousands
+// separators must group exactly three digits so a number written for another
+// locale is rejected rather than misread.
+func (l importLocale) parseNumber(value string, typed bool) (decimal.Decimal, error) {
+	if typed {
+		return decimal.NewFromString(value)
+	}
+
+	text := strings.TrimSpace(value)
+	negative := strings.HasPrefix(text, "-")
+	text = currencyPrefix.ReplaceAllString(strings.TrimPrefix(text, "-"), "")
+	if !negative && strings.HasPrefix(text, "-") {
+		negative = true
+		text = text[1:]
+	}
+	text = currencySuffix.ReplaceAllString(text, "")
+	if l.thousands == " " {
+		text = strings.NewReplacer("\u00a0", " ", "\u202f", " ").Replace(text)
+	}
+
+	if !l.number.MatchString(text) {
+		return decimal.Decimal{}, fmt.Errorf("unrecognized number format: %s", value)
+	}
+	if l.thousands != "" {
+		text = strings.ReplaceAll(text, l.thousands, "")
+	}
+	text = strings.Replace(text, l.decimal, ".", 1)
+
+	number, err := decimal.NewFromString(text)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if negative {
+		number = number.Neg()
+	}
+	return number, nil
+}
+
+func numberPattern(thousands, decimal string) *regexp.Regexp {
+	decimalPart := `(` + regexp.QuoteMeta(decimal) + `\d+)?`
+	if thousands == "" {
+		return regexp.MustCompile(`^\d+` + decimalPart + `$`)
+	}
+	return regexp.MustCompile(`^(\d+|\d{1,3}(` + regexp.QuoteMeta(thousands) + `\d{3})+)` + decimalPart + `$`)
+}
+
+// parseQuantity reads a whole number of items.
+func (l importLocale) parseQuantity(value string, typed bool) (int, error) {
+	number, err := l.parseNumber(value, typed)
+	if err != nil {
+		return 0, err
+	}
+	if !number.IsInteger() || number.Abs().GreaterThan(decimal.NewFromInt32(1<<31-1)) {
+		return 0, fmt.Errorf("quantity is not a whole number: %s", value)
+	}
+	return int(number.IntPart()), nil
+}
+
+// ambiguousDateError is returned for a day/month date that is valid either
+// way round when the import has no locale to decide between them.
+type ambiguousDateError struct {
+	dayFirst   time.Time
+	monthFirst time.Time
+}
+
+func (e *ambiguousDateError) Error() string {
+	return fmt.Sprintf("Date could be %s or %s, set a locale to choose",
+		e.dayFirst.Format("2 Jan 2006"), e.monthFirst.Format("2 Jan 2006"))
+}
+
+// parseDate reads an invoice date. Dates stored as Excel serial numbers, or
+// written as a serial number in text, are converted directly; text dates in
+// day/month order are read in the locale's order. Two-digit years follow Go's
+// rule: 69-99 are 19xx, the rest 20xx.
+func (l importLocale) parseDate(value string, typed bool) (time.Time, error) {
+	if typed || serialDate.MatchString(strings.TrimSpace(value)) {
+		serial, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	text := strings.TrimSpace(value)
+	for _, layout := range unambiguousDateLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
+	parts := numericDate.FindStringSubmatch(text)
+	if parts == nil || parts[2] != parts[4] {
+		return time.Time{}, fmt.Errorf("unrecognized date format: %s", value)
+	}
+	first, _ := strconv.Atoi(parts[1])
+	second, _ := strconv.Atoi(parts[3])
+	year, _ := strconv.Atoi(parts[5])
+	if len(parts[5]) == 2 {
+		if year >= 69 {
+			year += 1900
+		} else {
+			year += 2000
+		}
+	}
+
+	dayFirst, dayFirstOK := calendarDate(year, second, first)
+	monthFirst, monthFirstOK := calendarDate(year, first, second)
+	switch {
+	case l.order == dateOrderDMY && dayFirstOK:
+		return dayFirst, nil
+	case l.order == dateOrderMDY && monthFirstOK:
+		return monthFirst, nil
+	case l.order != dateOrderUnknown:
+		return time.Time{}, fmt.Errorf("invalid date: %s", value)
+	case dayFirstOK && monthFirstOK && first != second:
+		return time.Time{}, &ambiguousDateError{dayFirst: dayFirst, monthFirst: monthFirst}
+	case dayFirstOK:
+		return dayFirst, nil
+	case monthFirstOK:
+		return monthFirst, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", value)
+}
+
+func calendarDate(year, month, day int) (time.Time, bool) {
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return t, month >= 1 && month <= 12 && t.Day() == day && int(t.Month()) == month
+}
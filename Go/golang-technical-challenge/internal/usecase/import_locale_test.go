@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func mustImportLocale(t *testing.T, name string) importLocale {
+	t.Helper()
+	locale, err := importLocaleFor(name)
+	if err != nil {
+		t.Fatalf("importLocaleFor(%q): %v", name, err)
+	}
+	return locale
+}
+
+func TestImportLocaleFor(t *testing.T) {
+	if _, err := importLocaleFor("nl-NL"); err == nil {
+		t.Error("importLocaleFor(nl-NL) succeeded, want an unsupported locale error")
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		locale string
+		value  string
+		typed  bool
+		want   string
+		ok     bool
+	}{
+		{"", "1234.5", false, "1234.5", true},
+		{"", "1,234.5", false, "", false},
+		{"", "1.234,5", false, "", false},
+		{"en-US", "1,234.5", false, "1234.5", true},
+		{"en-US", "1.234,5", false, "", false},
+		{"en-US", "$1,500,000.00", false, "1500000", true},
+		{"en-US", "-1,234", false, "-1234", true},
+		{"en-US", "12,34", false, "", false},
+		{"id-ID", "1.234,5", false, "1234.5", true},
+		{"id-ID", "1,234.5", false, "", false},
+		{"id-ID", "Rp 25.000.000,00", false, "25000000", true},
+		{"id-ID", "Rp -1.500", false, "-1500", true},
+		{"de-DE", "1.234,5 €", false, "1234.5", true},
+		{"fr-FR", "1\u202f234,5", false, "1234.5", true},
+		{"fr-FR", "1\u00a0234,5", false, "1234.5", true},
+		{"fr-FR", "1 234,5", false, "1234.5", true},
+		// Numbers stored as numbers are read the same in every locale.
+		{"id-ID", "1234.5", true, "1234.5", true},
+		{"de-DE", "1.5E3", true, "1500", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.value, func(t *testing.T) {
+			got, err := mustImportLocale(t, tt.locale).parseNumber(tt.value, tt.typed)
+			if !tt.ok {
+				if err == nil {
+					t.Errorf("parseNumber(%q) = %s, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNumber(%q): %v", tt.value, err)
+			}
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("parseNumber(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		locale    string
+		value     string
+		typed     bool
+		want      string
+		ambiguous bool
+	}{
+		{"", "2025-04-03", false, "2025-04-03", false},
+		{"", "3 Apr 2025", false, "2025-04-03", false},
+		{"", "03/04/2025", false, "", true},
+		{"", "04/04/2025", false, "2025-04-04", false},
+		{"", "13/04/2025", false, "2025-04-13", false},
+		{"", "04/13/2025", false, "2025-04-13", false},
+		{"en-US", "03/04/2025", false, "2025-03-04", false},
+		{"en-GB", "03/04/2025", false, "2025-04-03", false},
+		{"de-DE", "03.04.25", false, "2025-04-03", false},
+		{"id-ID", "03-04-69", false, "1969-04-03", false},
+		// Serial dates, typed or written as text, read the same everywhere.
+		{"", "45901", true, "2025-09-01", false},
+		{"", "45901", false, "2025-09-01", false},
+		{"en-US", " 45901.75 ", false, "2025-09-01", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.value, func(t *testing.T) {
+			got, err := mustImportLocale(t, tt.locale).parseDate(tt.value, tt.typed)
+			var ambiguous *ambiguousDateError
+			if isAmbiguous := errors.As(err, &ambiguous); isAmbiguous != tt.ambiguous {
+				t.Fatalf("parseDate(%q) error = %v, want ambiguous %v", tt.value, err, tt.ambiguous)
+			}
+			if tt.ambiguous {
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDate(%q): %v", tt.value, err)
+			}
+			if got.Format("2006-01-02") != tt.want || got.Location() != time.UTC {
+				t.Errorf("parseDate(%q) = %v, want %s UTC", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateRejectsInvalidDates(t *testing.T) {
+	tests := []struct {
+		locale string
+		value  string
+	}{
+		{"", "31/02/2025"},
+		{"", "03/04-2025"},
+		{"", "next tuesday"},
+		{"en-US", "13/04/2025"},
+		{"en-GB", "04/13/2025"},
+		{"", "123456"},
+	}
+	for _, tt := range tests {
+		got, err := mustImportLocale(t, tt.locale).parseDate(tt.value, false)
+		if err == nil {
+			t.Errorf("parseDate(%q) in %q = %v, want an error", tt.value, tt.locale, got)
+		}
+		var ambiguous *ambiguousDateError
+		if errors.As(err, &ambiguous) {
+			t.Errorf("parseDate(%q) in %q is reported as ambiguous", tt.value, tt.locale)
+		}
+	}
+}
+
+func TestNumericDate(t *testing.T) {
+	tests := []struct {
+		value string
+		match bool
+	}{
+		{"03/04/2025", true},
+		{"3.4.25", true},
+		{"03-04-2025", true},
+		{"2025/04/03", false},
+		{"03/04/202", false},
+		{"003/04/2025", false},
+	}
+	for _, tt := range tests {
+		if got := numericDate.MatchString(tt.value); got != tt.match {
+			t.Errorf("numericDate matches %q = %v, want %v", tt.value, got, tt.match)
+		}
+	}
+}
+
+func TestAmbiguousDateErrorNamesBothReadings(t *testing.T) {
+	err := &ambiguousDateError{
+		dayFirst:   time.Date(2025, 4, 3, 0, 0, 0, 0, time.UTC),
+		monthFirst: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+	}
+	if got, want := err.Error(), "Date could be 3 Apr 2025 or 4 Mar 2025, set a locale to choose"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"golang-technical-challenge/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+type ImportProfileUseCase struct {
+	DB                      *gorm.DB
+	Log                     *logrus.Logger
+	Validate                *validator.Validate
+	ImportProfileRepository *repository.ImportProfileRepository
+}
+
+func NewImportProfileUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	importProfileRepository *repository.ImportProfileRepository,
+) *ImportProfileUseCase {
+	return &ImportProfileUseCase{
+		DB:                      db,
+		Log:                     logger,
+		Validate:                validate,
+		ImportProfileRepository: importProfileRepository,
+	}
+}
+
+func (c *ImportProfileUseCase) Create(ctx context.Context, request *model.CreateImportProfileRequest) (*model.ImportProfileResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid create import profile payload")
+		return nil, fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	existing := new(entity.ImportProfile)
+	if err := c.ImportProfileRepository.FindByName(tx, existing, request.Name); err == nil {
+		c.Log.WithField("name", request.Name).Warn("Import profile already exists")
+		return nil, fiber.NewError(fiber.StatusConflict, "Import profile already exists")
+	} else if err != gorm.ErrRecordNotFound {
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to check existing import profile")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	profile := &entity.ImportProfile{
+		Name:      request.Name,
+		CreatedAt: time.Now(),
+	}
+	if err := c.applyProfileSettings(profile, request.InvoiceSheet, request.ProductSheet, request.HeaderRow, request.MatchBy,
+		request.InvoiceColumns, request.ProductColumns); err != nil {
+		return nil, err
+	}
+
+	if err := c.ImportProfileRepository.Create(tx, profile); err != nil {
+		c.Log.WithError(err).WithField("name", profile.Name).Error("Failed to create import profile")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("name", profile.Name).Error("Failed to commit import profile creation")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ImportProfileToResponse(profile), nil
+}
+
+func (c *ImportProfileUseCase) Get(ctx context.Context, request *model.GetImportProfileRequest) (*model.ImportProfileResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid get import profile request")
+		return nil, fiber.ErrBadRequest
+	}
+
+	profile := new(entity.ImportProfile)
+	if err := c.ImportProfileRepository.FindByName(c.DB.WithContext(ctx), profile, request.Name); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Log.WithField("name", request.Name).Warn("Import profile not found")
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to fetch import profile")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ImportProfileToResponse(profile), nil
+}
+
+func (c *ImportProfileUseCase) List(ctx context.Context) ([]model.ImportProfileResponse, error) {
+	profiles, err := c.ImportProfileRepository.FindAll(c.DB.WithContext(ctx))
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ImportProfilesToResponseList(profiles), nil
+}
+
+func (c *ImportProfileUseCase) Update(ctx context.Context, request *model.UpdateImportProfileRequest) (*model.ImportProfileResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("name", request.Name).Warn("Invalid update import profile payload")
+		return nil, fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	profile := new(entity.ImportProfile)
+	if err := c.ImportProfileRepository.FindByName(tx, profile, request.Name); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Log.WithField("name", request.Name).Warn("Import profile not found")
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to fetch import profile for update")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.applyProfileSettings(profile, request.InvoiceSheet, request.ProductSheet, request.HeaderRow, request.MatchBy,
+		request.InvoiceColumns, request.ProductColumns); err != nil {
+		return nil, err
+	}
+
+	if err := c.ImportProfileRepository.Update(tx, profile); err != nil {
+		c.Log.WithError(err).WithField("name", profile.Name).Error("Failed to update import profile")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("name", profile.Name).Error("Failed to commit import profile update")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ImportProfileToResponse(profile), nil
+}
+
+func (c *ImportProfileUseCase) Delete(ctx context.Context, request *model.DeleteImportProfileRequest) error {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid delete import profile request")
+		return fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	profile := new(entity.ImportProfile)
+	if err := c.ImportProfileRepository.FindByName(tx, profile, request.Name); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Log.WithField("name", request.Name).Warn("Import profile not found")
+			return fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to fetch import profile for deletion")
+		return fiber.ErrInternalServerError
+	}
+
+	if err := c.ImportProfileRepository.Delete(tx, profile); err != nil {
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to delete import profile")
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("name", request.Name).Error("Failed to commit import profile deletion")
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// applyProfileSettings copies the request onto profile, filling unset values
+// from the default template. Profiles matching by column must map fields to
+// valid column letters.
+func (c *ImportProfileUseCase) applyProfileSettings(profile *entity.ImportProfile, invoiceSheet, productSheet string, headerRow int,
+	matchBy string, invoiceColumns, productColumns map[string]string,
+) error {
+	defaults := DefaultImportProfile()
+	profile.InvoiceSheet = defaultString(invoiceSheet, defaults.InvoiceSheet)
+	profile.ProductSheet = defaultString(productSheet, defaults.ProductSheet)
+	profile.HeaderRow = headerRow
+	if profile.HeaderRow == 0 {
+		profile.HeaderRow = defaults.HeaderRow
+	}
+	profile.MatchBy = defaultString(matchBy, defaults.MatchBy)
+	profile.UpdatedAt = time.Now()
+
+	if invoiceColumns == nil {
+		invoiceColumns = map[string]string{}
+	}
+	if productColumns == nil {
+		productColumns = map[string]string{}
+	}
+
+	if profile.MatchBy == model.ImportMatchByColumn {
+		for _, columns := range []map[string]string{invoiceColumns, productColumns} {
+			for field, column := range columns {
+				if _, err := excelize.ColumnNameToNumber(strings.TrimSpace(column)); err != nil {
+					return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid column letter %q for %s", column, field))
+				}
+			}
+		}
+	}
+
+	encodedInvoiceColumns, err := json.Marshal(invoiceColumns)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to encode import profile columns")
+		return fiber.ErrInternalServerError
+	}
+	encodedProductColumns, err := json.Marshal(productColumns)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to encode import profile columns")
+		return fiber.ErrInternalServerError
+	}
+	profile.InvoiceColumns = string(encodedInvoiceColumns)
+	profile.ProductColumns = string(encodedProductColumns)
+
+	return nil
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
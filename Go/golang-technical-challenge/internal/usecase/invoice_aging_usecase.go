@@ -0,0 +1,380 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	agingSheetCustomers    = "customers"
+	agingSheetSalespersons = "salespersons"
+	agingSheetInvoices     = "invoices"
+)
+
+var agingInvoiceHeader = []any{
+	"invoice no", "date", "due date", "customer", "salesperson", "payment terms",
+	"subtotal", "paid", "outstanding", "days overdue", "bucket",
+}
+
+// agingBucket returns the index in model.AgingBuckets of a balance that is
+// daysOverdue days past its due date.
+func agingBucket(daysOverdue int) int {
+	switch {
+	case daysOverdue <= 0:
+		return 0
+	case daysOverdue <= 30:
+		return 1
+	case daysOverdue <= 60:
+		return 2
+	case daysOverdue <= 90:
+		return 3
+	}
+	return 4
+}
+
+// agingTotals sums the outstanding balances of a group of invoices per
+// bucket of model.AgingBuckets.
+type agingTotals struct {
+	id       string
+	name     string
+	invoices int
+	buckets  [5]decimal.Decimal
+}
+
+func (t *agingTotals) add(bucket int, balance decimal.Decimal) {
+	t.invoices++
+	t.buckets[bucket] = t.buckets[bucket].Add(balance)
+}
+
+func (t *agingTotals) response() model.AgingBalance {
+	total := decimal.Zero
+	for _, amount := range t.buckets {
+		total = total.Add(amount)
+	}
+	return model.AgingBalance{
+		ID:         t.id,
+		Name:       t.name,
+		Invoices:   t.invoices,
+		Current:    converter.RoundAmount(t.buckets[0]),
+		Days1To30:  converter.RoundAmount(t.buckets[1]),
+		Days31To60: converter.RoundAmount(t.buckets[2]),
+		Days61To90: converter.RoundAmount(t.buckets[3]),
+		Over90:     converter.RoundAmount(t.buckets[4]),
+		Total:      converter.RoundAmount(total),
+	}
+}
+
+// agedInvoice is an open invoice placed in its aging bucket.
+type agedInvoice struct {
+	invoice     *model.InvoiceResponse
+	daysOverdue int
+	bucket      int
+}
+
+// agingReport ages open invoices as of a date, totalling them by customer
+// and by salesperson. Invoices of a registered customer or salesperson are
+// grouped by its ID, older ones without one by the name on the invoice.
+type agingReport struct {
+	asOf         time.Time
+	customers    map[string]*agingTotals
+	salespersons map[string]*agingTotals
+	total        agingTotals
+}
+
+func newAgingReport(asOf time.Time) *agingReport {
+	return &agingReport{
+		asOf:         asOf,
+		customers:    map[string]*agingTotals{},
+		salespersons: map[string]*agingTotals{},
+	}
+}
+
+// add ages invoice by the days between its due date and the report date.
+// Its outstanding balance is the one it had on the report date: its
+// PaidAmount only counts the payments received by then, and it may have been
+// paid off since.
+func (r *agingReport) add(invoice *entity.Invoice) agedInvoice {
+	response := converter.InvoiceToResponse(invoice)
+	balance := decimal.Zero
+	for i := range invoice.Products {
+		balance = balance.Add(invoice.Products[i].Revenue())
+	}
+	response.Outstanding = converter.RoundAmount(balance.Sub(invoice.PaidAmount))
+	daysOverdue := int(r.asOf.Sub(invoice.DueDate()).Hours() / 24)
+	bucket := agingBucket(daysOverdue)
+
+	customer := &agingTotals{name: invoice.CustomerName}
+	if invoice.CustomerID != nil {
+		customer.id = *invoice.CustomerID
+	}
+	addAging(r.customers, customer, bucket, response.Outstanding)
+	salesperson := &agingTotals{name: invoice.SalespersonName}
+	if invoice.SalespersonID != nil {
+		salesperson.id = *invoice.SalespersonID
+	}
+	addAging(r.salespersons, salesperson, bucket, response.Outstanding)
+	r.total.add(bucket, response.Outstanding)
+
+	return agedInvoice{invoice: response, daysOverdue: daysOverdue, bucket: bucket}
+}
+
+// addAging adds balance to the totals in groups of the same customer or
+// salesperson as group, which is added when there are none yet.
+func addAging(groups map[string]*agingTotals, group *agingTotals, bucket int, balance decimal.Decimal) {
+	key := "name:" + group.name
+	if group.id != "" {
+		key = "id:" + group.id
+	}
+	if groups[key] == nil {
+		groups[key] = group
+	}
+	groups[key].add(bucket, balance)
+}
+
+func (r *agingReport) response() *model.AgingReportResponse {
+	return &model.AgingReportResponse{
+		AsOf:         r.asOf.Format("2006-01-02"),
+		Customers:    agingGroups(r.customers),
+		Salespersons: agingGroups(r.salespersons),
+		Total:        r.total.response(),
+	}
+}
+
+// agingGroups lists groups by the largest total outstanding first, then by
+// name.
+func agingGroups(groups map[string]*agingTotals) []model.AgingBalance {
+	balances := make([]model.AgingBalance, 0, len(groups))
+	for _, totals := range groups {
+		balances = append(balances, totals.response())
+	}
+	slices.SortFunc(balances, func(a, b model.AgingBalance) int {
+		if c := b.Total.Cmp(a.Total); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return balances
+}
+
+// GetAging buckets the balances CREDIT invoices still had on the report date
+// by the days they were past their due date, grouped by customer and by
+// salesperson. Invoices dated after the report date are left out.
+func (c *InvoiceUseCase) GetAging(ctx context.Context, request *model.AgingReportRequest) (*model.AgingReportResponse, error) {
+	report, err := c.ageInvoices(ctx, request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return report.response(), nil
+}
+
+// ExportAging builds the aging report as a workbook with a sheet per
+// grouping and an invoices sheet listing every open invoice with its
+// bucket. Invoices are streamed into their sheet as they are read.
+func (c *InvoiceUseCase) ExportAging(ctx context.Context, request *model.AgingReportRequest) (*model.InvoiceExport, error) {
+	file := excelize.NewFile()
+	export := &agingExport{file: file}
+	if err := export.open(); err != nil {
+		file.Close()
+		c.Log.WithError(err).Error("Failed to create aging report workbook")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	report, err := c.ageInvoices(ctx, request, export.writeInvoice)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err := export.finish(report.response()); err != nil {
+		file.Close()
+		c.Log.WithError(err).Error("Failed to finish aging report workbook")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.InvoiceExport{
+		FileName:    fmt.Sprintf("aging-%s.xlsx", report.asOf.Format("2006-01-02")),
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		WriterTo:    export,
+		Closer:      export,
+	}, nil
+}
+
+// ageInvoices adds every open CREDIT invoice to an agingReport, calling fn,
+// when set, with each invoice as it is aged.
+func (c *InvoiceUseCase) ageInvoices(ctx context.Context, request *model.AgingReportRequest, fn func(aged *agedInvoice) error) (*agingReport, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid aging report request")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "as_of must be in YYYY-MM-DD format")
+	}
+	asOf := request.AsOf
+	if asOf == "" {
+		asOf = time.Now().Format("2006-01-02")
+	}
+	date, err := time.Parse("2006-01-02", asOf)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "as_of must be in YYYY-MM-DD format")
+	}
+
+	report := newAgingReport(date)
+	err = c.InvoiceRepository.FindOpenCreditInBatches(c.DB.WithContext(ctx), asOf, invoiceExportBatchSize, func(invoices []entity.Invoice) error {
+		for i := range invoices {
+			aged := report.add(&invoices[i])
+			if fn == nil {
+				continue
+			}
+			if err := fn(&aged); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.Log.WithError(err).WithField("as_of", asOf).Error("Failed to age credit invoices")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// Registered customers and salespersons are reported under their current
+	// name.
+	customers, err := c.CustomerRepository.FindByIDs(c.DB.WithContext(ctx), agingIDs(report.customers))
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+	for _, customer := range customers {
+		report.customers["id:"+customer.ID].name = customer.Name
+	}
+	salespersons, err := c.SalespersonRepository.FindByIDs(c.DB.WithContext(ctx), agingIDs(report.salespersons))
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+	for _, salesperson := range salespersons {
+		report.salespersons["id:"+salesperson.ID].name = salesperson.Name
+	}
+	return report, nil
+}
+
+// agingIDs lists the IDs of the groups that have one.
+func agingIDs(groups map[string]*agingTotals) []string {
+	ids := make([]string, 0, len(groups))
+	for _, totals := range groups {
+		if totals.id != "" {
+			ids = append(ids, totals.id)
+		}
+	}
+	return ids
+}
+
+// agingExport writes an aging report into a workbook. The grouped sheets
+// come first but are only written by finish, once every invoice is aged.
+type agingExport struct {
+	file       *excelize.File
+	invoices   *excelize.StreamWriter
+	dateStyle  int
+	invoiceRow int
+}
+
+func (e *agingExport) open() error {
+	if err := e.file.SetSheetName(e.file.GetSheetName(0), agingSheetCustomers); err != nil {
+		return err
+	}
+	for _, sheet := range []string{agingSheetSalespersons, agingSheetInvoices} {
+		if _, err := e.file.NewSheet(sheet); err != nil {
+			return err
+		}
+	}
+
+	dateFormat := "yyyy-mm-dd"
+	style, err := e.file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return err
+	}
+	e.dateStyle = style
+
+	e.invoiceRow = 1
+	e.invoices, err = newStreamSheet(e.file, agingSheetInvoices, agingInvoiceHeader)
+	return err
+}
+
+func (e *agingExport) writeInvoice(aged *agedInvoice) error {
+	invoice := aged.invoice
+	e.invoiceRow++
+	return e.invoices.SetRow(fmt.Sprintf("A%d", e.invoiceRow), []any{
+		invoice.InvoiceNo,
+		excelize.Cell{StyleID: e.dateStyle, Value: invoice.Date},
+		excelize.Cell{StyleID: e.dateStyle, Value: invoice.DueDate},
+		invoice.CustomerName,
+		invoice.SalespersonName,
+		invoice.PaymentTerms,
+		invoice.Subtotal.InexactFloat64(),
+		invoice.PaidAmount.InexactFloat64(),
+		invoice.Outstanding.InexactFloat64(),
+		max(aged.daysOverdue, 0),
+		model.AgingBuckets[aged.bucket],
+	})
+}
+
+// finish flushes the invoices sheet and writes the grouped sheets, each
+// ending with a total row.
+func (e *agingExport) finish(report *model.AgingReportResponse) error {
+	if err := e.invoices.Flush(); err != nil {
+		return err
+	}
+
+	total := report.Total
+	total.Name = "total"
+
+	for _, grouping := range []struct {
+		sheet, name string
+		balances    []model.AgingBalance
+	}{
+		{agingSheetCustomers, "customer", report.Customers},
+		{agingSheetSalespersons, "salesperson", report.Salespersons},
+	} {
+		header := []any{grouping.name, "invoices"}
+		for _, bucket := range model.AgingBuckets {
+			header = append(header, bucket)
+		}
+		writer, err := newStreamSheet(e.file, grouping.sheet, append(header, "total"))
+		if err != nil {
+			return err
+		}
+
+		rows := append(slices.Clone(grouping.balances), total)
+		for i, balance := range rows {
+			if err := writer.SetRow(fmt.Sprintf("A%d", i+2), []any{
+				balance.Name,
+				balance.Invoices,
+				balance.Current.InexactFloat64(),
+				balance.Days1To30.InexactFloat64(),
+				balance.Days31To60.InexactFloat64(),
+				balance.Days61To90.InexactFloat64(),
+				balance.Over90.InexactFloat64(),
+				balance.Total.InexactFloat64(),
+			}); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *agingExport) WriteTo(w io.Writer) (int64, error) {
+	return e.file.WriteTo(w)
+}
+
+func (e *agingExport) Close() error {
+	return e.file.Close()
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAgingBucket(t *testing.T) {
+	tests := []struct {
+		daysOverdue int
+		want        string
+	}{
+		{-10, "current"},
+		{0, "current"},
+		{1, "1-30"},
+		{30, "1-30"},
+		{31, "31-60"},
+		{60, "31-60"},
+		{61, "61-90"},
+		{90, "61-90"},
+		{91, "90+"},
+		{400, "90+"},
+	}
+	for _, tt := range tests {
+		if got := model.AgingBuckets[agingBucket(tt.daysOverdue)]; got != tt.want {
+			t.Errorf("agingBucket(%d) = %s, want %s", tt.daysOverdue, got, tt.want)
+		}
+	}
+}
+
+func TestAgingReportAgesByDueDate(t *testing.T) {
+	asOf := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		daysOverdue int
+		want        string
+	}{
+		{0, "current"},
+		{30, "1-30"},
+		{31, "31-60"},
+		{60, "31-60"},
+		{61, "61-90"},
+		{90, "61-90"},
+		{91, "90+"},
+	}
+	for _, tt := range tests {
+		// NET 30 invoices fall due 30 days after their date.
+		invoice := &entity.Invoice{
+			InvoiceNo:    "INV-1",
+			Date:         asOf.AddDate(0, 0, -30-tt.daysOverdue),
+			CustomerName: "Customer 1",
+			PaymentType:  "CREDIT",
+			PaymentTerms: 30,
+			Products:     []entity.Product{{Quantity: 3, TotalPrice: decimal.RequireFromString("333.335")}},
+			PaidAmount:   decimal.NewFromInt(100),
+		}
+		aged := newAgingReport(asOf).add(invoice)
+		if aged.daysOverdue != tt.daysOverdue || model.AgingBuckets[aged.bucket] != tt.want {
+			t.Errorf("invoice due %s is %d days overdue in %s, want %d in %s", invoice.DueDate().Format("2006-01-02"),
+				aged.daysOverdue, model.AgingBuckets[aged.bucket], tt.daysOverdue, tt.want)
+		}
+		if !aged.invoice.Outstanding.Equal(decimal.RequireFromString("900.01")) {
+			t.Errorf("outstanding = %s, want 900.01", aged.invoice.Outstanding)
+		}
+	}
+}
+
+func TestGetAgingCountsPaymentsUpToAsOf(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	now := time.Now()
+	exec := func(sql string, values ...any) {
+		t.Helper()
+		if err := useCase.DB.Exec(sql, values...).Error; err != nil {
+			t.Fatalf("%s: %v", sql, err)
+		}
+	}
+	invoice := func(invoiceNo, date, status string, price int) {
+		t.Helper()
+		exec(`INSERT INTO invoices (invoice_no, date, customer_name, salesperson_name, payment_type, payment_terms, status, created_at, updated_at)
+			VALUES (?, ?, 'Walk-in Customer', 'Sales 1', 'CREDIT', 30, ?, ?, ?)`,
+			invoiceNo, date, status, now, now)
+		exec(`INSERT INTO products (invoice_no, item_name, quantity, total_cost, total_price, created_at)
+			VALUES (?, 'Speaker', 1, 100, ?, ?)`, invoiceNo, price, now)
+	}
+	payment := func(invoiceNo, date string, amount int) {
+		t.Helper()
+		exec(`INSERT INTO payments (invoice_no, date, amount, method, created_at, updated_at) VALUES (?, ?, ?, 'cash', ?, ?)`,
+			invoiceNo, date, amount, now, now)
+	}
+
+	// Paid off after as_of: aged with the balance left on as_of.
+	invoice("INV-1", "2025-06-01", model.InvoiceStatusPaid, 1500)
+	payment("INV-1", "2025-07-15", 500)
+	payment("INV-1", "2025-10-01", 1000)
+	// Paid off by as_of: left out.
+	invoice("INV-2", "2025-06-01", model.InvoiceStatusPaid, 800)
+	payment("INV-2", "2025-08-01", 800)
+	// Dated after as_of: left out.
+	invoice("INV-3", "2025-09-20", model.InvoiceStatusIssued, 700)
+	// Void: left out.
+	invoice("INV-4", "2025-06-01", model.InvoiceStatusVoid, 900)
+
+	report, err := useCase.GetAging(context.Background(), &model.AgingReportRequest{AsOf: "2025-09-15"})
+	if err != nil {
+		t.Fatalf("GetAging: %v", err)
+	}
+
+	// INV-1 fell due on 2025-07-01, 76 days before as_of.
+	total := report.Total
+	if total.Invoices != 1 || !total.Days61To90.Equal(decimal.NewFromInt(1000)) || !total.Total.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("total = %+v, want one invoice with 1000 in 61-90", total)
+	}
+	if len(report.Customers) != 1 || report.Customers[0].Name != "Walk-in Customer" || report.Customers[0].ID != "" {
+		t.Errorf("customers = %+v, want Walk-in Customer by name", report.Customers)
+	}
+}
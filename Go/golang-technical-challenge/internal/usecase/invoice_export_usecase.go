@@ -0,0 +1,453 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	invoiceExportBatchSize = 500
+	invoiceExportSummary   = "summary"
+	// invoiceExportFlushEvery is how many invoices a CSV or NDJSON export
+	// writes between flushes, so consumers see rows while it runs.
+	invoiceExportFlushEvery = 100
+)
+
+var invoiceCSVHeader = []string{
+	"invoice_no", "date", "customer_id", "customer_name", "salesperson_id", "salesperson_name", "payment_type", "notes", "status", "payment_terms", "due_date",
+	"created_at", "updated_at",
+	"product_id", "item_name", "quantity", "total_cost", "total_price",
+}
+
+// invoiceExport writes invoices into the invoice and product sheets of the
+// default import profile, one stream writer per sheet.
+type invoiceExport struct {
+	file       *excelize.File
+	invoices   *excelize.StreamWriter
+	products   *excelize.StreamWriter
+	dateStyle  int
+	invoiceRow int
+	productRow int
+	count      int
+}
+
+// ExportInvoices builds a workbook of the issued and paid invoices matching
+// filter that ImportInvoices can read back unchanged, plus a summary sheet
+// with the totals GetInvoices reports for the same filter. The import layout
+// has no status column and imports every invoice as issued, so drafts and
+// void invoices are left out rather than brought back issued. excelize's
+// stream writers spool rows to temporary files, so the workbook is not held
+// in memory while it is built or written out.
+func (c *InvoiceUseCase) ExportInvoices(ctx context.Context, filter *model.InvoiceFilter) (*model.InvoiceExport, error) {
+	if err := c.Validate.Struct(filter); err != nil {
+		c.Log.WithError(err).Warn("Invalid invoice export filter")
+		return nil, fiber.NewError(fiber.StatusBadRequest, validationMessage("Invalid invoice filters", err))
+	}
+	if err := checkInvoiceFilterRanges(filter); err != nil {
+		return nil, err
+	}
+	if filter.Status == model.InvoiceStatusDraft || filter.Status == model.InvoiceStatusVoid {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Draft and void invoices cannot be exported in the import layout, use export.csv or export.ndjson")
+	}
+
+	export, err := newInvoiceExport()
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to create invoice export workbook")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	tx := c.DB.WithContext(ctx)
+
+	err = c.InvoiceRepository.FindInvoicesInBatches(tx, filter, invoiceExportBatchSize, func(invoices []entity.Invoice) error {
+		for i := range invoices {
+			if err := export.writeInvoice(&invoices[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		export.file.Close()
+		c.Log.WithError(err).WithField("filter", filter).Error("Failed to export invoices")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	totalProfit, totalCash, err := c.InvoiceRepository.GetSummary(tx, filter)
+	if err != nil {
+		export.file.Close()
+		c.Log.WithError(err).WithField("filter", filter).Error("Failed to calculate invoice summary")
+		return nil, fiber.ErrInternalServerError
+	}
+	totalCollected, err := c.InvoiceRepository.GetCollections(tx, filter)
+	if err != nil {
+		export.file.Close()
+		c.Log.WithError(err).WithField("filter", filter).Error("Failed to calculate credit collections")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := export.finish(filter, totalProfit, totalCash, totalCollected); err != nil {
+		export.file.Close()
+		c.Log.WithError(err).WithField("filter", filter).Error("Failed to finish invoice export workbook")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.InvoiceExport{
+		FileName:    invoiceExportFileName(filter),
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		WriterTo:    export,
+		Closer:      export,
+	}, nil
+}
+
+// ExportInvoiceLines validates request and returns an export that streams the
+// matching invoices as CSV, one row per product line, or as NDJSON, one
+// invoice per line with its products nested. The query only runs when the
+// export is written out, reading rows as they arrive from the database.
+func (c *InvoiceUseCase) ExportInvoiceLines(ctx context.Context, request *model.ExportInvoicesRequest) (*model.InvoiceExport, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid invoice export request")
+		return nil, fiber.NewError(fiber.StatusBadRequest, validationMessage("Invalid export filters", err))
+	}
+	if request.DateFrom != "" && request.DateTo != "" && request.DateTo < request.DateFrom {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "date_to must not be before date_from")
+	}
+
+	export := &invoiceLineExport{ctx: ctx, useCase: c, request: request}
+	contentType := "text/csv; charset=utf-8"
+	if request.Format == model.InvoiceExportNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	return &model.InvoiceExport{
+		FileName:    "invoices." + request.Format,
+		ContentType: contentType,
+		WriterTo:    export,
+		Closer:      export,
+	}, nil
+}
+
+// invoiceExportFileName names the workbook after the dates it covers.
+func invoiceExportFileName(filter *model.InvoiceFilter) string {
+	switch {
+	case filter.Date != "":
+		return fmt.Sprintf("invoices-%s.xlsx", filter.Date)
+	case filter.DateFrom != "" && filter.DateTo != "":
+		return fmt.Sprintf("invoices-%s-to-%s.xlsx", filter.DateFrom, filter.DateTo)
+	}
+	return "invoices.xlsx"
+}
+
+type invoiceLineExport struct {
+	ctx     context.Context
+	useCase *InvoiceUseCase
+	request *model.ExportInvoicesRequest
+}
+
+// WriteTo flushes w every invoiceExportFlushEvery invoices when it is
+// already a *bufio.Writer, as the response body writer is.
+func (e *invoiceLineExport) WriteTo(w io.Writer) (int64, error) {
+	buffered, ok := w.(*bufio.Writer)
+	if !ok {
+		buffered = bufio.NewWriter(w)
+	}
+	out := &countingWriter{w: buffered}
+
+	var write func(invoice *entity.Invoice) error
+	if e.request.Format == model.InvoiceExportNDJSON {
+		encoder := json.NewEncoder(out)
+		write = func(invoice *entity.Invoice) error {
+			return encoder.Encode(converter.InvoiceToResponse(invoice))
+		}
+	} else {
+		csvWriter := csv.NewWriter(out)
+		if err := csvWriter.Write(invoiceCSVHeader); err != nil {
+			return out.n, err
+		}
+		csvWriter.Flush()
+		write = func(invoice *entity.Invoice) error {
+			if err := writeInvoiceCSV(csvWriter, invoice); err != nil {
+				return err
+			}
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	}
+
+	written := 0
+	err := e.useCase.InvoiceRepository.EachInvoice(e.useCase.DB.WithContext(e.ctx), e.request, func(invoice *entity.Invoice) error {
+		if err := write(invoice); err != nil {
+			return err
+		}
+		written++
+		if written%invoiceExportFlushEvery == 0 {
+			return out.w.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return out.n, err
+	}
+	return out.n, out.w.Flush()
+}
+
+func (e *invoiceLineExport) Close() error {
+	return nil
+}
+
+// writeInvoiceCSV writes one row per product of invoice, repeating the
+// invoice columns. An invoice without products still gets one row.
+func writeInvoiceCSV(w *csv.Writer, invoice *entity.Invoice) error {
+	notes := ""
+	if invoice.Notes != nil {
+		notes = *invoice.Notes
+	}
+	customerID := ""
+	if invoice.CustomerID != nil {
+		customerID = *invoice.CustomerID
+	}
+	salespersonID := ""
+	if invoice.SalespersonID != nil {
+		salespersonID = *invoice.SalespersonID
+	}
+	header := []string{
+		invoice.InvoiceNo,
+		invoice.Date.Format("2006-01-02"),
+		customerID,
+		invoice.CustomerName,
+		salespersonID,
+		invoice.SalespersonName,
+		invoice.PaymentType,
+		notes,
+		invoice.Status,
+		strconv.Itoa(invoice.PaymentTerms),
+		invoice.DueDate().Format("2006-01-02"),
+		invoice.CreatedAt.Format(time.RFC3339Nano),
+		invoice.UpdatedAt.Format(time.RFC3339Nano),
+	}
+
+	if len(invoice.Products) == 0 {
+		return w.Write(append(header, "", "", "", "", ""))
+	}
+	for _, product := range invoice.Products {
+		row := append(header[:len(header):len(header)],
+			product.ID,
+			product.ItemName,
+			strconv.Itoa(product.Quantity),
+			product.TotalCost.String(),
+			product.TotalPrice.String(),
+		)
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written through it for WriteTo.
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func newInvoiceExport() (*invoiceExport, error) {
+	profile := DefaultImportProfile()
+	file := excelize.NewFile()
+	export := &invoiceExport{file: file, invoiceRow: 1, productRow: 1}
+
+	err := func() error {
+		if err := file.SetSheetName(file.GetSheetName(0), profile.InvoiceSheet); err != nil {
+			return err
+		}
+		for _, sheet := range []string{profile.ProductSheet, invoiceExportSummary} {
+			if _, err := file.NewSheet(sheet); err != nil {
+				return err
+			}
+		}
+
+		dateFormat := "yyyy-mm-dd"
+		style, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+		if err != nil {
+			return err
+		}
+		export.dateStyle = style
+
+		if export.invoices, err = newExportSheet(file, profile.InvoiceSheet, invoiceFields); err != nil {
+			return err
+		}
+		export.products, err = newExportSheet(file, profile.ProductSheet, productFields)
+		return err
+	}()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return export, nil
+}
+
+// newExportSheet opens a stream writer on sheet and writes the template
+// headers of fields as its first row.
+func newExportSheet(file *excelize.File, sheet string, fields []importField) (*excelize.StreamWriter, error) {
+	header := make([]any, len(fields))
+	for i, field := range fields {
+		header[i] = field.headers[0]
+	}
+	return newStreamSheet(file, sheet, header)
+}
+
+// newStreamSheet opens a stream writer on sheet and writes header as its
+// first row.
+func newStreamSheet(file *excelize.File, sheet string, header []any) (*excelize.StreamWriter, error) {
+	writer, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.SetColWidth(1, len(header), 18); err != nil {
+		return nil, err
+	}
+	if err := writer.SetRow("A1", header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// writeInvoice appends invoice to the invoice sheet and its products to the
+// product sheet. Dates and amounts are written as typed cells so they are
+// read back without depending on a locale.
+func (e *invoiceExport) writeInvoice(invoice *entity.Invoice) error {
+	var notes any
+	if invoice.Notes != nil {
+		notes = *invoice.Notes
+	}
+
+	e.invoiceRow++
+	e.count++
+	if err := e.invoices.SetRow(fmt.Sprintf("A%d", e.invoiceRow), []any{
+		invoice.InvoiceNo,
+		excelize.Cell{StyleID: e.dateStyle, Value: invoice.Date},
+		invoice.CustomerName,
+		invoice.SalespersonName,
+		invoice.PaymentType,
+		notes,
+		invoice.PaymentTerms,
+	}); err != nil {
+		return err
+	}
+
+	for _, product := range invoice.Products {
+		e.productRow++
+		if err := e.products.SetRow(fmt.Sprintf("A%d", e.productRow), []any{
+			invoice.InvoiceNo,
+			product.ItemName,
+			product.Quantity,
+			product.TotalCost.InexactFloat64(),
+			product.TotalPrice.InexactFloat64(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish flushes the streamed sheets and fills in the summary sheet: one row
+// per filter that was set, then the invoice count and totals.
+func (e *invoiceExport) finish(filter *model.InvoiceFilter, totalProfit, totalCash, totalCollected string) error {
+	if err := e.invoices.Flush(); err != nil {
+		return err
+	}
+	if err := e.products.Flush(); err != nil {
+		return err
+	}
+
+	profit, err := decimal.NewFromString(totalProfit)
+	if err != nil {
+		return err
+	}
+	cash, err := decimal.NewFromString(totalCash)
+	if err != nil {
+		return err
+	}
+	collected, err := decimal.NewFromString(totalCollected)
+	if err != nil {
+		return err
+	}
+
+	rows := append(invoiceFilterRows(filter),
+		[]any{"invoices", e.count},
+		[]any{"total profit", profit.InexactFloat64()},
+		[]any{"total cash", cash.InexactFloat64()},
+		[]any{"credit collected", collected.InexactFloat64()},
+	)
+	for i, row := range rows {
+		cell := fmt.Sprintf("A%d", i+1)
+		if err := e.file.SetSheetRow(invoiceExportSummary, cell, &row); err != nil {
+			return err
+		}
+		if _, isDate := row[1].(time.Time); isDate {
+			cell = fmt.Sprintf("B%d", i+1)
+			if err := e.file.SetCellStyle(invoiceExportSummary, cell, cell, e.dateStyle); err != nil {
+				return err
+			}
+		}
+	}
+	return e.file.SetColWidth(invoiceExportSummary, "A", "B", 18)
+}
+
+// invoiceFilterRows lists the filters that are set as summary rows, with
+// dates as typed cells.
+func invoiceFilterRows(filter *model.InvoiceFilter) [][]any {
+	var rows [][]any
+	for _, field := range []struct {
+		name  string
+		value string
+		date  bool
+	}{
+		{"date", filter.Date, true},
+		{"date from", filter.DateFrom, true},
+		{"date to", filter.DateTo, true},
+		{"customer id", filter.CustomerID, false},
+		{"customer name", filter.CustomerName, false},
+		{"salesperson id", filter.SalespersonID, false},
+		{"salesperson name", filter.SalespersonName, false},
+		{"payment type", filter.PaymentType, false},
+		{"status", filter.Status, false},
+		{"min total", filter.MinTotal, false},
+		{"max total", filter.MaxTotal, false},
+		{"q", filter.Q, false},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if date, err := time.Parse("2006-01-02", field.value); field.date && err == nil {
+			rows = append(rows, []any{field.name, date})
+			continue
+		}
+		rows = append(rows, []any{field.name, field.value})
+	}
+	return rows
+}
+
+func (e *invoiceExport) WriteTo(w io.Writer) (int64, error) {
+	return e.file.WriteTo(w)
+}
+
+func (e *invoiceExport) Close() error {
+	return e.file.Close()
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestInvoiceExportRoundTripsThroughImport(t *testing.T) {
+	export, err := newInvoiceExport()
+	if err != nil {
+		t.Fatalf("newInvoiceExport: %v", err)
+	}
+	defer export.Close()
+
+	// Invoices spread over two months, as a date range export writes them.
+	start := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	const count = 61 * 40
+	for i := 0; i < count; i++ {
+		notes := "Delivered to the warehouse"
+		invoice := &entity.Invoice{
+			InvoiceNo:       fmt.Sprintf("INV-%05d", i),
+			Date:            start.AddDate(0, 0, i%61),
+			CustomerName:    fmt.Sprintf("Customer %d", i%50),
+			SalespersonName: fmt.Sprintf("Sales %d", i%10),
+			PaymentType:     "CREDIT",
+			Notes:           &notes,
+			PaymentTerms:    30,
+			Products: []entity.Product{
+				{ItemName: "Product A", Quantity: 2, TotalCost: decimal.RequireFromString("1000.50"), TotalPrice: decimal.RequireFromString("1500.25")},
+				{ItemName: "Product B", Quantity: 1, TotalCost: decimal.RequireFromString("200"), TotalPrice: decimal.RequireFromString("350")},
+			},
+		}
+		if err := export.writeInvoice(invoice); err != nil {
+			t.Fatalf("writeInvoice: %v", err)
+		}
+	}
+	filter := &model.InvoiceFilter{DateFrom: "2025-08-01", DateTo: "2025-09-30"}
+	if err := export.finish(filter, "100.00", "0.00", "0.00"); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := export.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	data := buf.Bytes()
+
+	workbook, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("open export: %v", err)
+	}
+	summary, err := workbook.GetRows(invoiceExportSummary)
+	workbook.Close()
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	want := [][]string{{"date from", "2025-08-01"}, {"date to", "2025-09-30"}, {"invoices", fmt.Sprint(count)}}
+	for i, row := range want {
+		if len(summary) <= i || len(summary[i]) < 2 || summary[i][0] != row[0] || summary[i][1] != row[1] {
+			t.Fatalf("summary = %v, want it to start with %v", summary, want)
+		}
+	}
+
+	useCase := newTestInvoiceUseCase(t)
+	options := model.ImportOptions{Mode: model.ImportModeAtomic, OnConflict: model.ImportConflictError}
+	result, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+	if err != nil {
+		t.Fatalf("ImportInvoices: %v", err)
+	}
+	if result.Created != count || len(result.Errors) != 0 {
+		t.Fatalf("imported %d invoices with errors %+v, want %d", result.Created, result.Errors, count)
+	}
+
+	imported := result.Invoices[len(result.Invoices)-1]
+	if imported.Date.Format("2006-01-02") != "2025-08-01" || imported.Notes == nil || *imported.Notes != "Delivered to the warehouse" || imported.PaymentTerms != 30 {
+		t.Errorf("imported invoice = %+v", imported)
+	}
+	var products int64
+	useCase.DB.Table("products").Count(&products)
+	if products != 2*count {
+		t.Errorf("imported %d products, want %d", products, 2*count)
+	}
+}
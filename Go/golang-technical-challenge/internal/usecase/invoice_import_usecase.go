@@ -0,0 +1,1491 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+	textunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"gorm.io/gorm"
+)
+
+// importField describes one column of the import template: where it sits in
+// the template, the header names it is recognised by and whether it must be
+// present.
+type importField struct {
+	name     string
+	column   string
+	headers  []string
+	required bool
+}
+
+var invoiceFields = []importField{
+	{model.ImportFieldInvoiceNo, "A", []string{"invoice no", "invoice number", "no invoice", "invoice"}, true},
+	{model.ImportFieldDate, "B", []string{"date", "invoice date", "tanggal"}, true},
+	{model.ImportFieldCustomerName, "C", []string{"customer", "customer name", "pelanggan"}, true},
+	{model.ImportFieldSalespersonName, "D", []string{"salesperson", "salesperson name", "sales person", "sales"}, true},
+	{model.ImportFieldPaymentType, "E", []string{"payment type", "payment method", "payment"}, true},
+	{model.ImportFieldNotes, "F", []string{"notes", "note", "remarks", "keterangan"}, false},
+	{model.ImportFieldPaymentTerms, "G", []string{"payment terms", "terms", "top", "termin"}, false},
+}
+
+var productFields = []importField{
+	{model.ImportFieldInvoiceNo, "A", []string{"invoice no", "invoice number", "no invoice", "invoice"}, true},
+	{model.ImportFieldItemName, "B", []string{"item", "item name", "product", "product name"}, true},
+	{model.ImportFieldQuantity, "C", []string{"quantity", "qty"}, true},
+	{model.ImportFieldTotalCost, "D", []string{"total cogs", "total cost", "cogs", "cost"}, true},
+	{model.ImportFieldTotalPrice, "E", []string{"total price", "price"}, true},
+}
+
+// importColumns maps import fields to the column letters holding them in one
+// sheet. Optional fields that were not found map to "".
+type importColumns map[string]string
+
+// shortRow returns the column of the first required field that lies beyond
+// the end of row.
+func (c importColumns) shortRow(row []string, fields []importField) (string, bool) {
+	for _, field := range fields {
+		index, err := excelize.ColumnNameToNumber(c[field.name])
+		if field.required && err == nil && index > len(row) {
+			return c[field.name], true
+		}
+	}
+	return "", false
+}
+
+// DefaultImportProfile describes the standard import template: headers are
+// matched by name on the first row of the "invoice" and "product sold" sheets.
+func DefaultImportProfile() *model.ImportProfileResponse {
+	return &model.ImportProfileResponse{
+		InvoiceSheet:   "invoice",
+		ProductSheet:   "product sold",
+		HeaderRow:      1,
+		MatchBy:        model.ImportMatchByHeader,
+		InvoiceColumns: map[string]string{},
+		ProductColumns: map[string]string{},
+	}
+}
+
+// resolveImportColumns locates the fields of one sheet. Profiles matching by
+// column read the letters from the mapping and fall back to the template
+// positions; profiles matching by header look for the mapped header name, or
+// the known header names, in the header row.
+func resolveImportColumns(sheet string, header []string, fields []importField, profile *model.ImportProfileResponse, mapping map[string]string) (importColumns, error) {
+	columns := importColumns{}
+	if profile.MatchBy == model.ImportMatchByColumn {
+		for _, field := range fields {
+			column := field.column
+			if mapped, ok := mapping[field.name]; ok {
+				column = strings.ToUpper(strings.TrimSpace(mapped))
+			}
+			if _, err := excelize.ColumnNameToNumber(column); err != nil {
+				return nil, fmt.Errorf("invalid column %q for %s in sheet %s", column, field.name, sheet)
+			}
+			columns[field.name] = column
+		}
+		return columns, nil
+	}
+
+	positions := map[string]int{}
+	for i, value := range header {
+		name := normalizeHeader(value)
+		if _, seen := positions[name]; name != "" && !seen {
+			positions[name] = i + 1
+		}
+	}
+
+	var missing []string
+	for _, field := range fields {
+		candidates := field.headers
+		if mapped, ok := mapping[field.name]; ok {
+			candidates = []string{mapped}
+		}
+		for _, candidate := range candidates {
+			if position, ok := positions[normalizeHeader(candidate)]; ok {
+				columns[field.name], _ = excelize.ColumnNumberToName(position)
+				break
+			}
+		}
+		if columns[field.name] == "" && field.required {
+			missing = append(missing, fmt.Sprintf("%q", candidates[0]))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("sheet %s has no %s column on header row %d", sheet, strings.Join(missing, ", "), profile.HeaderRow)
+	}
+	return columns, nil
+}
+
+// normalizeHeader makes header matching ignore case, punctuation and spacing,
+// so "Invoice No.", "invoice_no" and "INVOICE  NO" are the same header.
+func normalizeHeader(value string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+const (
+	// importChunkSize is the number of rows checked against the database with
+	// a single IN query.
+	importChunkSize = 1000
+	// importBatchSize is the number of rows written by one multi-row INSERT.
+	importBatchSize = 500
+)
+
+// ImportProgressFunc receives the number of data rows read so far, the rows
+// handled so far and the number of errors collected so far. Rows are streamed,
+// so total only reaches the row count of the upload on the last call.
+type ImportProgressFunc func(total, processed, failed int)
+
+// importSource streams the invoice and product tables of an upload together
+// with the sheet names used when reporting errors. For CSV uploads the sheet
+// names are the file names inside the archive. rows opens a new iterator
+// over one of the two sheets each time it is called, so a sheet can be read
+// more than once.
+type importSource struct {
+	invoiceSheet string
+	productSheet string
+	rows         func(sheet string) (importRows, error)
+	close        func() error
+}
+
+func (s *importSource) Close() error {
+	return s.close()
+}
+
+// importRows iterates over the rows of one table, one spreadsheet row per
+// call to Next including empty ones, so rows can be numbered by counting. Row
+// returns the cells as displayed and, for workbooks, the values as stored, so
+// numbers and dates can be read without going through their display format.
+type importRows interface {
+	Next() bool
+	Row() (cells, typed []string, err error)
+	Err() error
+	Close() error
+}
+
+// xlsxRows adapts excelize's streaming row iterator. The sheet is walked
+// twice in step: once for display values and once for raw values.
+type xlsxRows struct {
+	rows *excelize.Rows
+	raw  *excelize.Rows
+}
+
+func (r *xlsxRows) Next() bool {
+	r.raw.Next()
+	return r.rows.Next()
+}
+
+func (r *xlsxRows) Row() ([]string, []string, error) {
+	cells, err := r.rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	typed, err := r.raw.Columns(excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cells, typed, nil
+}
+
+func (r *xlsxRows) Err() error {
+	if err := r.rows.Error(); err != nil {
+		return err
+	}
+	return r.raw.Error()
+}
+
+func (r *xlsxRows) Close() error {
+	if err := r.rows.Close(); err != nil {
+		r.raw.Close()
+		return err
+	}
+	return r.raw.Close()
+}
+
+type csvRows struct {
+	reader *csv.Reader
+	file   io.Closer
+	row    []string
+	err    error
+}
+
+func (r *csvRows) Next() bool {
+	r.row, r.err = r.reader.Read()
+	if r.err == io.EOF {
+		r.err = nil
+		return false
+	}
+	return true
+}
+
+func (r *csvRows) Row() ([]string, []string, error) { return r.row, nil, r.err }
+func (r *csvRows) Err() error                       { return nil }
+func (r *csvRows) Close() error                     { return r.file.Close() }
+
+// importRow is a data row together with its 1-based spreadsheet row number.
+// typed holds the stored cell values and is nil for CSV rows.
+type importRow struct {
+	num   int
+	cells []string
+	typed []string
+}
+
+// importState tracks one upload while its rows stream through: the sheet row
+// each invoice came from, which invoices were written and how many of their
+// products, and the errors found along the way. It holds invoice numbers
+// only; the invoices themselves are dropped once their chunk is written.
+// Invoices that already exist are either marked for replacing or recorded as
+// skipped, depending on onConflict.
+type importState struct {
+	invoiceSheet   string
+	productSheet   string
+	headerRow      int
+	atomic         bool
+	onConflict     string
+	customerMatch  string
+	locale         importLocale
+	invoiceColumns importColumns
+	productColumns importColumns
+	// invoices counts the products written for each invoice accepted so
+	// far, and written lists those invoices in the order they were written.
+	invoices     map[string]int
+	written      []string
+	invoiceRows  map[string]int
+	rejectedRows map[string]int
+	replacing    map[string]bool
+	skipped      map[string]int
+	// originals holds the stored invoices replaced so far, with their
+	// products, so that a replacement left without products can be undone.
+	originals map[string]*entity.Invoice
+	// validProducts holds the invoice numbers that have at least one valid
+	// row on the product sheet, and withoutProducts the invoices rejected
+	// for having none.
+	validProducts   map[string]bool
+	withoutProducts map[string]bool
+	errors          []model.ImportError
+	warnings        []model.ImportError
+	rowsRead        int
+	onRow           func()
+}
+
+// readSheet opens sheet of source and reads it through readChunks.
+func (s *importState) readSheet(source *importSource, sheet string, onHeader func(header []string) error, onChunk func(chunk []importRow) error) error {
+	rows, err := source.rows(sheet)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return s.readChunks(rows, onHeader, onChunk)
+}
+
+// readChunks skips to the header row and passes it to onHeader, then hands
+// the data rows to onChunk in chunks of importChunkSize. Blank rows are
+// skipped. Chunks are reused between calls.
+func (s *importState) readChunks(rows importRows, onHeader func(header []string) error, onChunk func(chunk []importRow) error) error {
+	chunk := make([]importRow, 0, importChunkSize)
+	rowNum := 0
+	for rows.Next() {
+		rowNum++
+		cells, typed, err := rows.Row()
+		if err != nil {
+			return fmt.Errorf("cannot read row %d: %w", rowNum, err)
+		}
+
+		switch {
+		case rowNum < s.headerRow:
+			continue
+		case rowNum == s.headerRow:
+			if err := onHeader(cells); err != nil {
+				return err
+			}
+			continue
+		case isBlankRow(cells):
+			continue
+		}
+
+		chunk = append(chunk, importRow{num: rowNum, cells: cells, typed: typed})
+		if len(chunk) == importChunkSize {
+			if err := onChunk(chunk); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(chunk) > 0 {
+		return onChunk(chunk)
+	}
+	return nil
+}
+
+func isBlankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *importState) addError(err model.ImportError) {
+	s.errors = append(s.errors, err)
+}
+
+// halted reports whether an atomic import has already found an error. Its
+// transaction will be rolled back, so nothing more is written.
+func (s *importState) halted() bool {
+	return s.atomic && len(s.errors) > 0
+}
+
+// invoiceError reports a problem with an invoice that was already accepted
+// from the invoice sheet, pointing at the row it came from.
+func (s *importState) invoiceError(invoiceNo, code, message string) {
+	s.addError(model.ImportError{
+		Code:      code,
+		Sheet:     s.invoiceSheet,
+		Row:       s.invoiceRows[invoiceNo],
+		Column:    s.invoiceColumns[model.ImportFieldInvoiceNo],
+		Value:     invoiceNo,
+		InvoiceNo: invoiceNo,
+		Message:   message,
+	})
+}
+
+// rejectInvoice takes back an accepted invoice that could not be written, so
+// its products are reported as belonging to a rejected invoice.
+func (s *importState) rejectInvoice(invoiceNo, code, message string) {
+	s.invoiceError(invoiceNo, code, message)
+	delete(s.invoices, invoiceNo)
+	s.rejectedRows[invoiceNo] = s.invoiceRows[invoiceNo]
+}
+
+// ImportInvoices reads an upload of size bytes and writes its invoices in one
+// transaction while the rows stream in, one chunk at a time, so memory use
+// does not grow with the number of rows. Invoices are written before their
+// products are read, so the product sheet is scanned once beforehand to find
+// the invoices that have a valid product at all. Atomic imports with errors
+// and dry runs roll the transaction back at the end.
+func (c *InvoiceUseCase) ImportInvoices(ctx context.Context, file io.ReaderAt, size int64, options model.ImportOptions, progress ImportProgressFunc) (*model.ImportResult, error) {
+	profile := options.Profile
+	if profile == nil {
+		profile = DefaultImportProfile()
+	}
+
+	locale, err := importLocaleFor(options.Locale)
+	if err != nil {
+		c.Log.WithError(err).WithField("locale", options.Locale).Warn("Invalid import options")
+		return nil, err
+	}
+
+	source, err := loadImportSource(options.Format, file, size, profile)
+	if err != nil {
+		c.Log.WithError(err).WithField("format", options.Format).Error("Failed to read import file")
+		return nil, err
+	}
+	defer source.Close()
+
+	state := &importState{
+		invoiceSheet:    source.invoiceSheet,
+		productSheet:    source.productSheet,
+		headerRow:       profile.HeaderRow,
+		atomic:          options.Mode == model.ImportModeAtomic,
+		onConflict:      options.OnConflict,
+		customerMatch:   options.CustomerMatch,
+		locale:          locale,
+		invoices:        map[string]int{},
+		written:         []string{},
+		invoiceRows:     map[string]int{},
+		rejectedRows:    map[string]int{},
+		replacing:       map[string]bool{},
+		skipped:         map[string]int{},
+		originals:       map[string]*entity.Invoice{},
+		validProducts:   map[string]bool{},
+		withoutProducts: map[string]bool{},
+		errors:          []model.ImportError{},
+	}
+
+	processed := 0
+	state.onRow = func() {
+		processed++
+		if progress != nil {
+			progress(state.rowsRead, processed, len(state.errors))
+		}
+	}
+
+	readProducts := func(onChunk func(chunk []importRow) error) error {
+		return state.readSheet(source, state.productSheet, func(header []string) error {
+			state.productColumns, err = resolveImportColumns(state.productSheet, header, productFields, profile, profile.ProductColumns)
+			return err
+		}, onChunk)
+	}
+
+	if err := readProducts(func(chunk []importRow) error {
+		c.scanProductRows(chunk, state)
+		return nil
+	}); err != nil {
+		c.Log.WithError(err).WithField("sheet", state.productSheet).Error("Failed to read product rows")
+		return nil, err
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	err = state.readSheet(source, state.invoiceSheet, func(header []string) error {
+		state.invoiceColumns, err = resolveImportColumns(state.invoiceSheet, header, invoiceFields, profile, profile.InvoiceColumns)
+		return err
+	}, func(chunk []importRow) error {
+		state.rowsRead += len(chunk)
+		invoices, err := c.parseInvoiceRows(tx, chunk, state)
+		if err != nil {
+			return err
+		}
+		c.persistInvoices(tx, invoices, state)
+		return nil
+	})
+	if err != nil {
+		c.Log.WithError(err).WithField("sheet", state.invoiceSheet).Error("Failed to read invoice rows")
+		return nil, err
+	}
+
+	if err := readProducts(func(chunk []importRow) error {
+		state.rowsRead += len(chunk)
+		products, rows := c.parseProductRows(chunk, state)
+		c.persistProducts(tx, products, rows, state)
+		return nil
+	}); err != nil {
+		c.Log.WithError(err).WithField("sheet", state.productSheet).Error("Failed to read product rows")
+		return nil, err
+	}
+
+	if err := c.dropEmptyInvoices(tx, state); err != nil {
+		c.Log.WithError(err).Error("Failed to remove invoices without products")
+		return nil, err
+	}
+
+	if progress != nil {
+		progress(state.rowsRead, processed, len(state.errors))
+	}
+
+	rolledBack := state.halted()
+	invoiceNos := state.written
+	if rolledBack {
+		c.Log.WithField("error_count", len(state.errors)).Warn("Rolling back atomic import")
+		invoiceNos = []string{}
+	}
+
+	invoices := make([]entity.Invoice, 0, len(invoiceNos))
+	for start := 0; start < len(invoiceNos); start += importChunkSize {
+		chunk, err := c.InvoiceRepository.FindInvoicesByNumbers(tx, invoiceNos[start:min(start+importChunkSize, len(invoiceNos))])
+		if err != nil {
+			c.Log.WithError(err).Error("Failed to retrieve imported invoices")
+			return nil, err
+		}
+		invoices = append(invoices, chunk...)
+	}
+	sortInvoicesForListing(invoices)
+	sortImportErrors(state.errors, state.invoiceSheet)
+	sortImportErrors(state.warnings, state.invoiceSheet)
+	totalProfit, totalCash := summarizeInvoices(invoices)
+
+	if options.DryRun {
+		wouldCreate := make([]entity.Invoice, 0, len(invoices))
+		wouldUpdate := make([]entity.Invoice, 0)
+		for _, invoice := range invoices {
+			if state.replacing[invoice.InvoiceNo] {
+				wouldUpdate = append(wouldUpdate, invoice)
+			} else {
+				wouldCreate = append(wouldCreate, invoice)
+			}
+		}
+
+		return &model.ImportResult{
+			DryRun:      true,
+			Mode:        options.Mode,
+			RolledBack:  rolledBack,
+			Invoices:    []model.InvoiceResponse{},
+			WouldCreate: converter.InvoicesToResponseList(wouldCreate),
+			WouldUpdate: converter.InvoicesToResponseList(wouldUpdate),
+			Created:     len(wouldCreate),
+			Updated:     len(wouldUpdate),
+			Skipped:     len(state.skipped),
+			TotalProfit: totalProfit.StringFixed(2),
+			TotalCash:   totalCash.StringFixed(2),
+			Errors:      state.errors,
+			Warnings:    state.warnings,
+		}, nil
+	}
+
+	if !rolledBack {
+		if err := tx.Commit().Error; err != nil {
+			c.Log.WithError(err).Error("Failed to commit import")
+			return nil, err
+		}
+	}
+	if len(state.errors) > 0 {
+		c.Log.WithField("error_count", len(state.errors)).Warn("Import completed with errors")
+	}
+
+	createdNos := make([]string, 0, len(invoiceNos))
+	for _, invoiceNo := range invoiceNos {
+		if !state.replacing[invoiceNo] {
+			createdNos = append(createdNos, invoiceNo)
+		}
+	}
+
+	return &model.ImportResult{
+		Mode:        options.Mode,
+		RolledBack:  rolledBack,
+		Invoices:    converter.InvoicesToResponseList(invoices),
+		Created:     len(createdNos),
+		Updated:     len(invoiceNos) - len(createdNos),
+		Skipped:     len(state.skipped),
+		TotalProfit: totalProfit.StringFixed(2),
+		TotalCash:   totalCash.StringFixed(2),
+		Errors:      state.errors,
+		Warnings:    state.warnings,
+
+		CreatedInvoiceNos: createdNos,
+	}, nil
+}
+
+// persistInvoices writes one chunk of parsed invoices, without products,
+// inside the import transaction tx. New invoices are inserted importBatchSize
+// at a time; when a batch fails it is retried invoice by invoice behind
+// savepoints so the failing invoices can be reported. Replacements are
+// written one by one. Once an atomic import has an error nothing more is
+// written.
+func (c *InvoiceUseCase) persistInvoices(tx *gorm.DB, invoices []entity.Invoice, state *importState) {
+	creates := make([]entity.Invoice, 0, len(invoices))
+	replaces := make([]entity.Invoice, 0)
+	for _, invoice := range invoices {
+		if state.replacing[invoice.InvoiceNo] {
+			replaces = append(replaces, invoice)
+		} else {
+			creates = append(creates, invoice)
+		}
+	}
+
+	save := func(invoice *entity.Invoice) {
+		err := c.InvoiceRepository.InSavepoint(tx, "import_invoice", func(tx *gorm.DB) error {
+			return c.saveImportedInvoice(tx, invoice, state)
+		})
+		if err != nil {
+			state.rejectInvoice(invoice.InvoiceNo, model.ImportErrorSaveFailed, "Failed to save invoice")
+			return
+		}
+		state.written = append(state.written, invoice.InvoiceNo)
+	}
+
+	for start := 0; start < len(creates) && !state.halted(); start += importBatchSize {
+		batch := creates[start:min(start+importBatchSize, len(creates))]
+		err := c.InvoiceRepository.InSavepoint(tx, "import_batch", func(tx *gorm.DB) error {
+			return c.InvoiceRepository.CreateInBatches(tx, batch, importBatchSize)
+		})
+		if err == nil {
+			for _, invoice := range batch {
+				state.written = append(state.written, invoice.InvoiceNo)
+			}
+			continue
+		}
+
+		for i := 0; i < len(batch) && !state.halted(); i++ {
+			save(&batch[i])
+		}
+	}
+	for i := 0; i < len(replaces) && !state.halted(); i++ {
+		save(&replaces[i])
+	}
+}
+
+// persistProducts writes one chunk of parsed products, found on the given
+// sheet rows, the same way persistInvoices writes invoices.
+func (c *InvoiceUseCase) persistProducts(tx *gorm.DB, products []entity.Product, rows []int, state *importState) {
+	for start := 0; start < len(products) && !state.halted(); start += importBatchSize {
+		end := min(start+importBatchSize, len(products))
+		batch := products[start:end]
+		err := c.InvoiceRepository.InSavepoint(tx, "import_batch", func(tx *gorm.DB) error {
+			return c.InvoiceRepository.CreateProducts(tx, batch, importBatchSize)
+		})
+		if err == nil {
+			for _, product := range batch {
+				state.invoices[product.InvoiceNo]++
+			}
+			continue
+		}
+
+		for i := start; i < end && !state.halted(); i++ {
+			err := c.InvoiceRepository.InSavepoint(tx, "import_product", func(tx *gorm.DB) error {
+				return c.InvoiceRepository.CreateProducts(tx, products[i:i+1], 1)
+			})
+			if err != nil {
+				state.addError(model.ImportError{
+					Code:      model.ImportErrorSaveFailed,
+					Sheet:     state.productSheet,
+					Row:       rows[i],
+					Column:    state.productColumns[model.ImportFieldItemName],
+					Value:     products[i].ItemName,
+					InvoiceNo: products[i].InvoiceNo,
+					Message:   "Failed to save product",
+				})
+				continue
+			}
+			state.invoices[products[i].InvoiceNo]++
+		}
+	}
+}
+
+// dropEmptyInvoices deletes the invoices created by the import none of whose
+// products could be written, rather than leave them issued with nothing on
+// them, and puts back the invoices such replacements overwrote. Both are
+// reported with the same error.
+func (c *InvoiceUseCase) dropEmptyInvoices(tx *gorm.DB, state *importState) error {
+	if state.halted() {
+		return nil
+	}
+
+	written := make([]string, 0, len(state.written))
+	var empty, rejected []string
+	for _, invoiceNo := range state.written {
+		if state.invoices[invoiceNo] > 0 {
+			written = append(written, invoiceNo)
+			continue
+		}
+		if state.replacing[invoiceNo] {
+			if err := c.InvoiceRepository.Restore(tx, state.originals[invoiceNo]); err != nil {
+				return err
+			}
+		} else {
+			empty = append(empty, invoiceNo)
+		}
+		rejected = append(rejected, invoiceNo)
+	}
+
+	for start := 0; start < len(empty); start += importChunkSize {
+		if err := c.InvoiceRepository.DeleteByNumbers(tx, empty[start:min(start+importChunkSize, len(empty))]); err != nil {
+			return err
+		}
+	}
+	for _, invoiceNo := range rejected {
+		state.rejectInvoice(invoiceNo, model.ImportErrorSaveFailed, "Failed to save invoice, none of its products could be saved")
+	}
+	state.written = written
+	return nil
+}
+
+// saveImportedInvoice creates invoice, or replaces the stored invoice with the
+// same number when the import runs with on_conflict=replace. Replacing drops
+// the stored products, keeping a copy in state; the imported ones are written
+// afterwards.
+func (c *InvoiceUseCase) saveImportedInvoice(tx *gorm.DB, invoice *entity.Invoice, state *importState) error {
+	if !state.replacing[invoice.InvoiceNo] {
+		return c.InvoiceRepository.Create(tx, invoice)
+	}
+
+	original := new(entity.Invoice)
+	if err := c.InvoiceRepository.FindByInvoiceNo(tx, original, invoice.InvoiceNo); err != nil {
+		return err
+	}
+	if err := c.InvoiceRepository.Replace(tx, invoice); err != nil {
+		return err
+	}
+	state.originals[invoice.InvoiceNo] = original
+	return nil
+}
+
+// summarizeInvoices totals profit and cash the way GetSummary does, leaving
+// out drafts and void invoices.
+func summarizeInvoices(invoices []entity.Invoice) (totalProfit, totalCash decimal.Decimal) {
+	totalProfit = decimal.Zero
+	totalCash = decimal.Zero
+	for _, inv := range invoices {
+		if inv.Status == model.InvoiceStatusDraft || inv.Status == model.InvoiceStatusVoid {
+			continue
+		}
+		for _, p := range inv.Products {
+			totalProfit = totalProfit.Add(p.Profit())
+			if inv.PaymentType == "CASH" {
+				totalCash = totalCash.Add(p.Revenue())
+			}
+		}
+	}
+	return totalProfit, totalCash
+}
+
+// sortInvoicesForListing orders invoices the same way FindInvoicesByNumbers does.
+func sortInvoicesForListing(invoices []entity.Invoice) {
+	sort.SliceStable(invoices, func(i, j int) bool {
+		if !invoices[i].Date.Equal(invoices[j].Date) {
+			return invoices[i].Date.After(invoices[j].Date)
+		}
+		return invoices[i].InvoiceNo < invoices[j].InvoiceNo
+	})
+}
+
+// sortImportErrors orders errors by sheet (invoice sheet first), then row.
+func sortImportErrors(errors []model.ImportError, invoiceSheet string) {
+	sort.SliceStable(errors, func(i, j int) bool {
+		if errors[i].Sheet != errors[j].Sheet {
+			return errors[i].Sheet == invoiceSheet
+		}
+		return errors[i].Row < errors[j].Row
+	})
+}
+
+// Mirrors the CHECK constraints on the invoices table so that violations are
+// reported per row instead of failing the INSERT.
+func invoiceConstraintError(invoice *entity.Invoice, columns importColumns) *model.ImportError {
+	switch {
+	case utf8.RuneCountInString(invoice.InvoiceNo) > 50:
+		return &model.ImportError{Code: model.ImportErrorInvoiceNoTooLong, Column: columns[model.ImportFieldInvoiceNo], Value: invoice.InvoiceNo,
+			Message: "Invoice number must be at most 50 characters"}
+	case utf8.RuneCountInString(invoice.CustomerName) < 2 || utf8.RuneCountInString(invoice.CustomerName) > 255:
+		return &model.ImportError{Code: model.ImportErrorInvalidCustomerName, Column: columns[model.ImportFieldCustomerName], Value: invoice.CustomerName,
+			Message: "Customer name must be between 2 and 255 characters"}
+	case utf8.RuneCountInString(invoice.SalespersonName) < 2 || utf8.RuneCountInString(invoice.SalespersonName) > 255:
+		return &model.ImportError{Code: model.ImportErrorInvalidSalespersonName, Column: columns[model.ImportFieldSalespersonName], Value: invoice.SalespersonName,
+			Message: "Salesperson name must be between 2 and 255 characters"}
+	case invoice.Notes != nil && utf8.RuneCountInString(*invoice.Notes) < 5:
+		return &model.ImportError{Code: model.ImportErrorNotesTooShort, Column: columns[model.ImportFieldNotes], Value: *invoice.Notes,
+			Message: "Notes must be at least 5 characters"}
+	}
+	return nil
+}
+
+// parsePaymentTerms reads a payment terms cell such as "30" or "NET 30". An
+// empty cell gives CREDIT invoices the customer's terms and CASH invoices
+// none.
+func parsePaymentTerms(value, paymentType string, customerTerms int) (int, bool) {
+	value = strings.TrimSpace(strings.ToUpper(value))
+	if value == "" {
+		if paymentType == "CREDIT" {
+			return customerTerms, true
+		}
+		return 0, true
+	}
+	terms, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(value, "NET")))
+	if err != nil || terms < 0 || terms > 365 || (paymentType != "CREDIT" && terms != 0) {
+		return 0, false
+	}
+	return terms, true
+}
+
+// Mirrors the CHECK constraints and DECIMAL(12,2) columns on the products table.
+func productConstraintError(product *entity.Product, columns importColumns) *model.ImportError {
+	maxAmount := decimal.New(1, 10)
+	switch {
+	case utf8.RuneCountInString(product.ItemName) < 5 || utf8.RuneCountInString(product.ItemName) > 255:
+		return &model.ImportError{Code: model.ImportErrorInvalidItemName, Column: columns[model.ImportFieldItemName], Value: product.ItemName,
+			Message: "Item name must be between 5 and 255 characters"}
+	case product.Quantity < 1:
+		return &model.ImportError{Code: model.ImportErrorInvalidQuantity, Column: columns[model.ImportFieldQuantity], Value: strconv.Itoa(product.Quantity),
+			Message: "Quantity must be at least 1"}
+	case product.TotalCost.IsNegative() || product.TotalCost.Round(2).GreaterThanOrEqual(maxAmount):
+		return &model.ImportError{Code: model.ImportErrorInvalidTotalCost, Column: columns[model.ImportFieldTotalCost], Value: product.TotalCost.String(),
+			Message: "Total cost must be between 0 and 9999999999.99"}
+	case product.TotalPrice.IsNegative() || product.TotalPrice.Round(2).GreaterThanOrEqual(maxAmount):
+		return &model.ImportError{Code: model.ImportErrorInvalidTotalPrice, Column: columns[model.ImportFieldTotalPrice], Value: product.TotalPrice.String(),
+			Message: "Total price must be between 0 and 9999999999.99"}
+	}
+	return nil
+}
+
+func loadImportSource(format string, file io.ReaderAt, size int64, profile *model.ImportProfileResponse) (*importSource, error) {
+	switch format {
+	case model.ImportFormatCSV:
+		return loadCSVSource(file, size)
+	case model.ImportFormatXLSX, "":
+		return loadXLSXSource(file, size, profile.InvoiceSheet, profile.ProductSheet)
+	}
+	return nil, fmt.Errorf("unsupported import format: %s", format)
+}
+
+// openImportWorkbook opens the upload as a workbook. CSV uploads are copied
+// into a new workbook with one sheet per file, named like in error reports.
+func openImportWorkbook(format string, data []byte) (*excelize.File, error) {
+	if format != model.ImportFormatCSV {
+		return excelize.OpenReader(bytes.NewReader(data))
+	}
+
+	source, err := loadCSVSource(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	xlsx := excelize.NewFile()
+	defaultSheet := xlsx.GetSheetName(0)
+	for _, sheet := range []string{source.invoiceSheet, source.productSheet} {
+		if err := copyCSVSheet(xlsx, source, sheet); err != nil {
+			xlsx.Close()
+			return nil, err
+		}
+	}
+	if err := xlsx.DeleteSheet(defaultSheet); err != nil {
+		xlsx.Close()
+		return nil, err
+	}
+	return xlsx, nil
+}
+
+func copyCSVSheet(xlsx *excelize.File, source *importSource, sheet string) error {
+	rows, err := source.rows(sheet)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if _, err := xlsx.NewSheet(sheet); err != nil {
+		return err
+	}
+	for rowNum := 1; rows.Next(); rowNum++ {
+		row, _, err := rows.Row()
+		if err != nil {
+			return err
+		}
+		cells := make([]any, len(row))
+		for j, value := range row {
+			cells[j] = value
+		}
+		if err := xlsx.SetSheetRow(sheet, fmt.Sprintf("A%d", rowNum), &cells); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// loadXLSXSource opens the workbook and reads its sheets with excelize's row
+// iterator, which decodes the sheet XML as rows are requested instead of
+// loading whole sheets. excelize keeps the compressed workbook in memory and
+// moves large worksheets to temporary files when it opens them.
+func loadXLSXSource(file io.ReaderAt, size int64, invoiceSheet, productSheet string) (*importSource, error) {
+	xlsx, err := excelize.OpenReader(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse XLSX file: %w", err)
+	}
+
+	for _, sheet := range []struct{ kind, name string }{{"invoice", invoiceSheet}, {"product", productSheet}} {
+		if index, err := xlsx.GetSheetIndex(sheet.name); err != nil || index < 0 {
+			xlsx.Close()
+			return nil, fmt.Errorf("cannot read %s sheet %q: %w", sheet.kind, sheet.name, excelize.ErrSheetNotExist{SheetName: sheet.name})
+		}
+	}
+
+	return &importSource{
+		invoiceSheet: invoiceSheet,
+		productSheet: productSheet,
+		rows: func(sheet string) (importRows, error) {
+			rows, err := openXLSXRows(xlsx, sheet)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read sheet %q: %w", sheet, err)
+			}
+			return rows, nil
+		},
+		close: xlsx.Close,
+	}, nil
+}
+
+func openXLSXRows(xlsx *excelize.File, sheet string) (*xlsxRows, error) {
+	rows, err := xlsx.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := xlsx.Rows(sheet)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &xlsxRows{rows: rows, raw: raw}, nil
+}
+
+// loadCSVSource reads a zip archive holding the invoice and product tables as
+// CSV or TSV files. The files are told apart by name: one must contain
+// "invoice" and the other "product". They are decompressed as their rows are
+// read.
+func loadCSVSource(file io.ReaderAt, size int64) (*importSource, error) {
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open CSV archive: %w", err)
+	}
+
+	invoiceFile, productFile := findCSVFiles(archive)
+	if invoiceFile == nil || productFile == nil {
+		return nil, fmt.Errorf("CSV archive must contain an invoices file and a products file")
+	}
+
+	source := &importSource{
+		invoiceSheet: csvSheetName(invoiceFile.Name),
+		productSheet: csvSheetName(productFile.Name),
+		close:        func() error { return nil },
+	}
+	source.rows = func(sheet string) (importRows, error) {
+		file := productFile
+		if sheet == source.invoiceSheet {
+			file = invoiceFile
+		}
+		rows, err := readCSVFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", file.Name, err)
+		}
+		return rows, nil
+	}
+	return source, nil
+}
+
+func findCSVFiles(archive *zip.Reader) (invoiceFile, productFile *zip.File) {
+	for _, file := range archive.File {
+		name := strings.ToLower(path.Base(file.Name))
+		if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		switch ext := path.Ext(name); {
+		case ext != ".csv" && ext != ".tsv" && ext != ".txt":
+			continue
+		case strings.Contains(name, "invoice") && invoiceFile == nil:
+			invoiceFile = file
+		case strings.Contains(name, "product") && productFile == nil:
+			productFile = file
+		}
+	}
+	return invoiceFile, productFile
+}
+
+// csvSheetName names the sheet that stands in for a CSV file in error reports
+// and annotated workbooks, which limit sheet names to 31 characters.
+func csvSheetName(name string) string {
+	name = path.Base(name)
+	if utf8.RuneCountInString(name) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	return name
+}
+
+// readCSVFile streams the rows of file. The file is read through once first
+// to choose its encoding, so the rows can then be decoded as they are read.
+func readCSVFile(file *zip.File) (importRows, error) {
+	decode, err := csvDecoder(file)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &csvRows{reader: parseCSV(decode(f)), file: f}, nil
+}
+
+// csvHeadSize is how much of a CSV file is looked at to detect its delimiter.
+const csvHeadSize = 64 * 1024
+
+// parseCSV reads delimited text exported by spreadsheet and POS tools,
+// detecting comma, semicolon, tab and pipe delimiters.
+func parseCSV(text io.Reader) *csv.Reader {
+	buffered := bufio.NewReaderSize(text, csvHeadSize)
+	head, _ := buffered.Peek(csvHeadSize)
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = detectCSVDelimiter(head)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader
+}
+
+// csvDecoder chooses how to decode file into UTF-8. Byte order marks are
+// stripped, and a file that is not valid UTF-8 anywhere in it is read as
+// Windows-1252. The returned function wraps a fresh reader of the file.
+func csvDecoder(file *zip.File) (func(r io.Reader) io.Reader, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	bom, _ := reader.Peek(3)
+	switch {
+	case bytes.HasPrefix(bom, []byte{0xEF, 0xBB, 0xBF}):
+		return func(r io.Reader) io.Reader {
+			buffered := bufio.NewReader(r)
+			buffered.Discard(3)
+			return buffered
+		}, nil
+	case bytes.HasPrefix(bom, []byte{0xFF, 0xFE}), bytes.HasPrefix(bom, []byte{0xFE, 0xFF}):
+		return func(r io.Reader) io.Reader {
+			return transform.NewReader(r, textunicode.UTF16(textunicode.LittleEndian, textunicode.ExpectBOM).NewDecoder())
+		}, nil
+	}
+
+	for {
+		r, size, err := reader.ReadRune()
+		if err == io.EOF {
+			return func(r io.Reader) io.Reader { return r }, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r == utf8.RuneError && size == 1 {
+			return func(r io.Reader) io.Reader {
+				return transform.NewReader(r, charmap.Windows1252.NewDecoder())
+			}, nil
+		}
+	}
+}
+
+// detectCSVDelimiter picks the candidate that occurs most often outside quotes
+// on the header line.
+func detectCSVDelimiter(text []byte) rune {
+	header, _, _ := bytes.Cut(text, []byte("\n"))
+
+	counts := map[rune]int{}
+	quoted := false
+	for _, r := range string(header) {
+		switch r {
+		case '"':
+			quoted = !quoted
+		case ',', ';', '\t', '|':
+			if !quoted {
+				counts[r]++
+			}
+		}
+	}
+
+	delimiter := ','
+	for _, candidate := range []rune{';', '\t', '|'} {
+		if counts[candidate] > counts[delimiter] {
+			delimiter = candidate
+		}
+	}
+	return delimiter
+}
+
+// cellAt returns the value in the given column letter of row, or "" when the
+// row is shorter than that.
+func cellAt(row []string, column string) string {
+	index, err := excelize.ColumnNameToNumber(column)
+	if err != nil || index > len(row) {
+		return ""
+	}
+	return row[index-1]
+}
+
+// findImportCustomers looks up the customers named in a chunk of invoice
+// rows under the job's match rule, keyed by customerMatchKey.
+func (c *InvoiceUseCase) findImportCustomers(db *gorm.DB, chunk []importRow, state *importState) (map[string]entity.Customer, error) {
+	names := make([]string, 0, len(chunk))
+	for _, row := range chunk {
+		if name := strings.TrimSpace(cellAt(row.cells, state.invoiceColumns[model.ImportFieldCustomerName])); name != "" {
+			names = append(names, name)
+		}
+	}
+	found, err := c.CustomerRepository.FindByMatch(db, state.customerMatch, names)
+	if err != nil {
+		return nil, err
+	}
+
+	customers := make(map[string]entity.Customer, len(found))
+	for _, customer := range found {
+		key := customer.Name
+		if state.customerMatch == model.CustomerMatchCode {
+			key = customer.Code
+		}
+		customers[customerMatchKey(state.customerMatch, key)] = customer
+	}
+	return customers, nil
+}
+
+// customerMatchKey is the form of a customer cell that match compares.
+func customerMatchKey(match, value string) string {
+	if match == model.CustomerMatchExact || match == model.CustomerMatchCode {
+		return strings.TrimSpace(value)
+	}
+	return entity.NameKey(value)
+}
+
+// findImportSalespersons looks up the salespersons named in a chunk of
+// invoice rows, keyed by entity.NameKey of their name.
+func (c *InvoiceUseCase) findImportSalespersons(db *gorm.DB, chunk []importRow, state *importState) (map[string]entity.Salesperson, error) {
+	names := make([]string, 0, len(chunk))
+	for _, row := range chunk {
+		if name := strings.TrimSpace(cellAt(row.cells, state.invoiceColumns[model.ImportFieldSalespersonName])); name != "" {
+			names = append(names, name)
+		}
+	}
+	found, err := c.SalespersonRepository.FindByNames(db, names)
+	if err != nil {
+		return nil, err
+	}
+
+	salespersons := make(map[string]entity.Salesperson, len(found))
+	for _, salesperson := range found {
+		salespersons[entity.NameKey(salesperson.Name)] = salesperson
+	}
+	return salespersons, nil
+}
+
+// parseInvoiceRows validates one chunk of invoice rows and returns the
+// invoices to write. Invoice numbers that already exist are looked up for the
+// whole chunk at once.
+func (c *InvoiceUseCase) parseInvoiceRows(db *gorm.DB, chunk []importRow, state *importState) ([]entity.Invoice, error) {
+	columns := state.invoiceColumns
+
+	invoiceNos := make([]string, 0, len(chunk))
+	for _, row := range chunk {
+		if invoiceNo := strings.TrimSpace(cellAt(row.cells, columns[model.ImportFieldInvoiceNo])); invoiceNo != "" {
+			invoiceNos = append(invoiceNos, invoiceNo)
+		}
+	}
+	found, err := c.InvoiceRepository.FindExistingByNumbers(db, invoiceNos)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]entity.Invoice, len(found))
+	for _, invoice := range found {
+		existing[invoice.InvoiceNo] = invoice
+	}
+
+	customers, err := c.findImportCustomers(db, chunk, state)
+	if err != nil {
+		return nil, err
+	}
+	salespersons, err := c.findImportSalespersons(db, chunk, state)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]entity.Invoice, 0, len(chunk))
+	for _, chunkRow := range chunk {
+		state.onRow()
+		row, rowNum := chunkRow.cells, chunkRow.num
+		invoiceNo := strings.TrimSpace(cellAt(row, columns[model.ImportFieldInvoiceNo]))
+		rowError := func(code, column, value, message string) {
+			state.addError(model.ImportError{
+				Code:      code,
+				Sheet:     state.invoiceSheet,
+				Row:       rowNum,
+				Column:    column,
+				Value:     value,
+				InvoiceNo: invoiceNo,
+				Message:   message,
+			})
+			if _, accepted := state.invoices[invoiceNo]; invoiceNo != "" && !accepted {
+				state.rejectedRows[invoiceNo] = rowNum
+			}
+		}
+
+		if column, short := columns.shortRow(row, invoiceFields); short {
+			rowError(model.ImportErrorMissingColumns, column, "", "Missing invoice fields")
+			continue
+		}
+
+		customer := cellAt(row, columns[model.ImportFieldCustomerName])
+		sales := cellAt(row, columns[model.ImportFieldSalespersonName])
+		rawPaymentType := cellAt(row, columns[model.ImportFieldPaymentType])
+		paymentType := strings.ToUpper(rawPaymentType)
+
+		requiredColumn := ""
+		switch {
+		case invoiceNo == "":
+			requiredColumn = columns[model.ImportFieldInvoiceNo]
+		case customer == "":
+			requiredColumn = columns[model.ImportFieldCustomerName]
+		case sales == "":
+			requiredColumn = columns[model.ImportFieldSalespersonName]
+		case paymentType == "":
+			requiredColumn = columns[model.ImportFieldPaymentType]
+		}
+		if requiredColumn != "" {
+			c.Log.WithFields(logrus.Fields{
+				"row":       rowNum,
+				"invoiceNo": invoiceNo,
+				"column":    requiredColumn,
+			}).Warn("Required invoice fields missing")
+			rowError(model.ImportErrorRequiredField, requiredColumn, "", "Required invoice fields are missing")
+			continue
+		}
+
+		if paymentType != "CASH" && paymentType != "CREDIT" {
+			rowError(model.ImportErrorInvalidPaymentType, columns[model.ImportFieldPaymentType], rawPaymentType, "Invalid payment type")
+			continue
+		}
+
+		var notes *string
+		if value := cellAt(row, columns[model.ImportFieldNotes]); strings.TrimSpace(value) != "" {
+			notes = &value
+		}
+
+		// A customer name that is not registered is kept by name only, but
+		// a code has to belong to a customer.
+		var customerID *string
+		customerName, customerTerms := customer, model.DefaultPaymentTerms
+		if matched, ok := customers[customerMatchKey(state.customerMatch, customer)]; ok {
+			customerID, customerName, customerTerms = &matched.ID, matched.Name, matched.PaymentTerms
+		} else if state.customerMatch == model.CustomerMatchCode {
+			rowError(model.ImportErrorUnknownCustomer, columns[model.ImportFieldCustomerName], customer, "No customer has this code")
+			continue
+		}
+
+		// A salesperson that is not registered is kept by name only.
+		var salespersonID *string
+		salespersonName := sales
+		if salesperson, ok := salespersons[entity.NameKey(sales)]; ok {
+			salespersonID, salespersonName = &salesperson.ID, salesperson.Name
+		}
+
+		rawTerms := cellAt(row, columns[model.ImportFieldPaymentTerms])
+		terms, ok := parsePaymentTerms(rawTerms, paymentType, customerTerms)
+		if !ok {
+			rowError(model.ImportErrorInvalidPaymentTerms, columns[model.ImportFieldPaymentTerms], rawTerms,
+				"Payment terms must be a number of days between 0 and 365, such as 30 or NET 30, and CASH invoices cannot have any")
+			continue
+		}
+
+		dateStr := cellAt(row, columns[model.ImportFieldDate])
+		parsedDate, err := state.locale.parseDate(typedCellAt(chunkRow, columns[model.ImportFieldDate]))
+		if ambiguous := (*ambiguousDateError)(nil); errors.As(err, &ambiguous) {
+			rowError(model.ImportErrorAmbiguousDate, columns[model.ImportFieldDate], dateStr, ambiguous.Error())
+			continue
+		}
+		if err != nil {
+			c.Log.WithFields(logrus.Fields{
+				"row":       rowNum,
+				"invoiceNo": invoiceNo,
+				"date":      dateStr,
+			}).Warn("Invalid date format")
+			rowError(model.ImportErrorInvalidDate, columns[model.ImportFieldDate], dateStr, "Invalid date format")
+			continue
+		}
+
+		if _, ok := state.invoiceRows[invoiceNo]; ok {
+			c.Log.WithField("invoice_no", invoiceNo).Warn("Duplicate invoice in file")
+			rowError(model.ImportErrorDuplicateInFile, columns[model.ImportFieldInvoiceNo], invoiceNo,
+				fmt.Sprintf("Duplicate invoice in file, first seen on row %d", state.invoiceRows[invoiceNo]))
+			continue
+		}
+
+		stored, exists := existing[invoiceNo]
+		if exists && state.onConflict == model.ImportConflictSkip {
+			state.skipped[invoiceNo] = rowNum
+			continue
+		}
+		if exists && state.onConflict != model.ImportConflictReplace {
+			c.Log.WithField("invoice_no", invoiceNo).Warn("Duplicate invoice")
+			rowError(model.ImportErrorDuplicateInvoice, columns[model.ImportFieldInvoiceNo], invoiceNo, "Duplicate invoice")
+			continue
+		}
+
+		// Issued invoices are replaced too, so a corrected file can be
+		// imported again, until they have been paid.
+		if exists && stored.Status != model.InvoiceStatusDraft && stored.Status != model.InvoiceStatusIssued {
+			rowError(model.ImportErrorInvoiceNotEditable, columns[model.ImportFieldInvoiceNo], invoiceNo,
+				fmt.Sprintf("Invoice is %s, only draft and issued invoices can be replaced", stored.Status))
+			continue
+		}
+		if exists && stored.PaidAmount.IsPositive() {
+			rowError(model.ImportErrorInvoiceNotEditable, columns[model.ImportFieldInvoiceNo], invoiceNo,
+				"Invoice has payments and can no longer be replaced")
+			continue
+		}
+
+		// Imported invoices record sales that have already been made.
+		invoice := &entity.Invoice{
+			InvoiceNo:       invoiceNo,
+			Date:            parsedDate,
+			CustomerID:      customerID,
+			CustomerName:    customerName,
+			SalespersonID:   salespersonID,
+			SalespersonName: salespersonName,
+			PaymentType:     paymentType,
+			Notes:           notes,
+			PaymentTerms:    terms,
+			Status:          model.InvoiceStatusIssued,
+			Products:        []entity.Product{},
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+
+		if importErr := invoiceConstraintError(invoice, columns); importErr != nil {
+			c.Log.WithFields(logrus.Fields{
+				"row":       rowNum,
+				"invoiceNo": invoiceNo,
+			}).Warn(importErr.Message)
+			rowError(importErr.Code, importErr.Column, importErr.Value, importErr.Message)
+			continue
+		}
+
+		state.invoiceRows[invoiceNo] = rowNum
+		if !state.validProducts[invoiceNo] {
+			state.withoutProducts[invoiceNo] = true
+			state.invoiceError(invoiceNo, model.ImportErrorNoValidProducts, "No valid products for this invoice")
+			continue
+		}
+
+		if exists {
+			invoice.CreatedAt = stored.CreatedAt
+			invoice.Status = stored.Status
+			state.replacing[invoiceNo] = true
+		}
+		if customerID == nil {
+			state.warnings = append(state.warnings, model.ImportError{
+				Code:      model.ImportErrorUnknownCustomer,
+				Sheet:     state.invoiceSheet,
+				Row:       rowNum,
+				Column:    columns[model.ImportFieldCustomerName],
+				Value:     customer,
+				InvoiceNo: invoiceNo,
+				Message:   "Customer is not registered, the invoice keeps the name without a customer",
+			})
+		}
+
+		state.invoices[invoiceNo] = 0
+		invoices = append(invoices, *invoice)
+	}
+
+	return invoices, nil
+}
+
+// scanProductRows records which invoices have at least one valid row in a
+// chunk of product rows, before any invoice is written.
+func (c *InvoiceUseCase) scanProductRows(chunk []importRow, state *importState) {
+	columns := state.productColumns
+	for _, row := range chunk {
+		if _, short := columns.shortRow(row.cells, productFields); short {
+			continue
+		}
+		if _, importErr := state.parseProduct(row); importErr == nil {
+			state.validProducts[strings.TrimSpace(cellAt(row.cells, columns[model.ImportFieldInvoiceNo]))] = true
+		}
+	}
+}
+
+// parseProductRows validates one chunk of product rows and returns the
+// products to write together with the rows they came from.
+func (c *InvoiceUseCase) parseProductRows(chunk []importRow, state *importState) ([]entity.Product, []int) {
+	columns := state.productColumns
+
+	products := make([]entity.Product, 0, len(chunk))
+	rows := make([]int, 0, len(chunk))
+	for _, chunkRow := range chunk {
+		state.onRow()
+		row, rowNum := chunkRow.cells, chunkRow.num
+		invoiceNo := strings.TrimSpace(cellAt(row, columns[model.ImportFieldInvoiceNo]))
+		rowError := func(code, column, value, message string) {
+			state.addError(model.ImportError{
+				Code:      code,
+				Sheet:     state.productSheet,
+				Row:       rowNum,
+				Column:    column,
+				Value:     value,
+				InvoiceNo: invoiceNo,
+				Message:   message,
+			})
+		}
+
+		if column, short := columns.shortRow(row, productFields); short {
+			rowError(model.ImportErrorMissingColumns, column, "", "Missing product fields")
+			continue
+		}
+
+		if _, skipped := state.skipped[invoiceNo]; skipped {
+			continue
+		}
+
+		// The products of an invoice rejected for having no valid products
+		// are each reported with what is wrong with them below.
+		_, accepted := state.invoices[invoiceNo]
+		if !accepted && !state.withoutProducts[invoiceNo] {
+			if invoiceRow, rejected := state.rejectedRows[invoiceNo]; rejected {
+				rowError(model.ImportErrorInvoiceRejected, columns[model.ImportFieldInvoiceNo], invoiceNo,
+					fmt.Sprintf("Product belongs to invoice rejected on row %d of the invoice sheet", invoiceRow))
+				continue
+			}
+			c.Log.WithFields(logrus.Fields{
+				"row":       rowNum,
+				"invoiceNo": invoiceNo,
+			}).Warn("Product refers to unknown invoice")
+			rowError(model.ImportErrorUnknownInvoiceRef, columns[model.ImportFieldInvoiceNo], invoiceNo, "Product refers to unknown invoice")
+			continue
+		}
+
+		product, importErr := state.parseProduct(chunkRow)
+		if importErr != nil {
+			c.Log.WithFields(logrus.Fields{
+				"row":       rowNum,
+				"invoiceNo": invoiceNo,
+			}).Warn(importErr.Message)
+			rowError(importErr.Code, importErr.Column, importErr.Value, importErr.Message)
+			continue
+		}
+		if !accepted {
+			continue
+		}
+
+		products = append(products, *product)
+		rows = append(rows, rowNum)
+	}
+	return products, rows
+}
+
+// parseProduct reads the product on row, which must have every required
+// column.
+func (s *importState) parseProduct(row importRow) (*entity.Product, *model.ImportError) {
+	columns := s.productColumns
+	invoiceNo := strings.TrimSpace(cellAt(row.cells, columns[model.ImportFieldInvoiceNo]))
+	item := cellAt(row.cells, columns[model.ImportFieldItemName])
+	qtyStr := cellAt(row.cells, columns[model.ImportFieldQuantity])
+	costStr := cellAt(row.cells, columns[model.ImportFieldTotalCost])
+	priceStr := cellAt(row.cells, columns[model.ImportFieldTotalPrice])
+
+	qty, err := s.locale.parseQuantity(typedCellAt(row, columns[model.ImportFieldQuantity]))
+	if err != nil {
+		return nil, &model.ImportError{Code: model.ImportErrorInvalidQuantity, Column: columns[model.ImportFieldQuantity], Value: qtyStr,
+			Message: "Invalid product quantity"}
+	}
+	cost, err := s.locale.parseNumber(typedCellAt(row, columns[model.ImportFieldTotalCost]))
+	if err != nil {
+		return nil, &model.ImportError{Code: model.ImportErrorInvalidTotalCost, Column: columns[model.ImportFieldTotalCost], Value: costStr,
+			Message: "Invalid product total cost"}
+	}
+	price, err := s.locale.parseNumber(typedCellAt(row, columns[model.ImportFieldTotalPrice]))
+	if err != nil {
+		return nil, &model.ImportError{Code: model.ImportErrorInvalidTotalPrice, Column: columns[model.ImportFieldTotalPrice], Value: priceStr,
+			Message: "Invalid product total price"}
+	}
+
+	product := &entity.Product{
+		InvoiceNo:  invoiceNo,
+		ItemName:   item,
+		Quantity:   qty,
+		TotalCost:  cost,
+		TotalPrice: price,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if importErr := productConstraintError(product, columns); importErr != nil {
+		return nil, importErr
+	}
+	return product, nil
+}
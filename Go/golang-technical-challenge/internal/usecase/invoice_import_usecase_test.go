@@ -0,0 +1,615 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/repository"
+	"io"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+	textunicode "golang.org/x/text/encoding/unicode"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testSchema is the part of the schema the importer touches, written for
+// SQLite. The check on customer_name stands in for a database constraint the
+// importer does not validate itself, so tests can make single rows fail.
+var testSchema = []string{
+	`CREATE TABLE customers (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		code TEXT NOT NULL,
+		name TEXT NOT NULL,
+		name_key TEXT,
+		payment_terms INTEGER NOT NULL DEFAULT 30,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`,
+	`CREATE TABLE salespersons (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		code TEXT NOT NULL,
+		name TEXT NOT NULL,
+		name_key TEXT,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`,
+	`CREATE TABLE invoices (
+		invoice_no TEXT PRIMARY KEY,
+		date DATETIME NOT NULL,
+		customer_id TEXT,
+		customer_name TEXT NOT NULL CHECK (customer_name <> 'Rejected Customer'),
+		salesperson_id TEXT,
+		salesperson_name TEXT NOT NULL,
+		payment_type TEXT NOT NULL CHECK (payment_type IN ('CASH', 'CREDIT')),
+		notes TEXT,
+		payment_terms INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL DEFAULT 'draft',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE products (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		invoice_no TEXT NOT NULL REFERENCES invoices (invoice_no) ON DELETE CASCADE,
+		item_name TEXT NOT NULL,
+		quantity INTEGER NOT NULL CHECK (quantity >= 1),
+		total_cost DECIMAL(12,2) NOT NULL,
+		total_price DECIMAL(12,2) NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME
+	)`,
+	`CREATE TABLE payments (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		invoice_no TEXT NOT NULL REFERENCES invoices (invoice_no) ON DELETE RESTRICT,
+		date DATETIME NOT NULL,
+		amount DECIMAL(12,2) NOT NULL CHECK (amount > 0),
+		method TEXT NOT NULL,
+		reference TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`,
+}
+
+var testDBs atomic.Int64
+
+// newTestDB opens a fresh in-memory database with testSchema.
+func newTestDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	dsn := fmt.Sprintf("file:test%d?mode=memory&cache=shared&_pragma=foreign_keys(1)", testDBs.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		tb.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		tb.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	tb.Cleanup(func() { sqlDB.Close() })
+
+	for _, statement := range testSchema {
+		if err := db.Exec(statement).Error; err != nil {
+			tb.Fatalf("create schema: %v", err)
+		}
+	}
+	return db
+}
+
+// newTestInvoiceUseCase works on a fresh database that knows the customers
+// and salespersons importWorkbook refers to.
+func newTestInvoiceUseCase(tb testing.TB) *InvoiceUseCase {
+	tb.Helper()
+	db := newTestDB(tb)
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("Customer %d", i)
+		if err := db.Exec("INSERT INTO customers (code, name, name_key) VALUES (?, ?, ?)",
+			fmt.Sprintf("CUST-%05d", i+1), name, entity.NameKey(name)).Error; err != nil {
+			tb.Fatalf("create customer: %v", err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("Sales %d", i)
+		if err := db.Exec("INSERT INTO salespersons (code, name, name_key) VALUES (?, ?, ?)",
+			fmt.Sprintf("SP-%05d", i+1), name, entity.NameKey(name)).Error; err != nil {
+			tb.Fatalf("create salesperson: %v", err)
+		}
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewInvoiceUseCase(db, log, validator.New(),
+		repository.NewInvoiceRepository(log),
+		repository.NewCustomerRepository(log),
+		repository.NewSalespersonRepository(log),
+	)
+}
+
+// importWorkbook builds an upload in the standard template with invoices
+// invoices of productsEach products each. customer, when set, may return a
+// customer name to use instead of the generated one for invoice i.
+func importWorkbook(tb testing.TB, invoices, productsEach int, customer func(i int) string) []byte {
+	tb.Helper()
+	file := excelize.NewFile()
+	defer file.Close()
+
+	write := func(sheet string, header []any, rows func(write func(row []any))) {
+		if _, err := file.NewSheet(sheet); err != nil {
+			tb.Fatalf("create sheet: %v", err)
+		}
+		stream, err := file.NewStreamWriter(sheet)
+		if err != nil {
+			tb.Fatalf("create sheet: %v", err)
+		}
+		rowNum := 1
+		add := func(row []any) {
+			cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+			if err := stream.SetRow(cell, row); err != nil {
+				tb.Fatalf("write row: %v", err)
+			}
+			rowNum++
+		}
+		add(header)
+		rows(add)
+		if err := stream.Flush(); err != nil {
+			tb.Fatalf("write sheet: %v", err)
+		}
+	}
+
+	write("invoice", []any{"invoice no", "date", "customer", "salesperson", "payment type", "notes"}, func(add func(row []any)) {
+		for i := 0; i < invoices; i++ {
+			name := fmt.Sprintf("Customer %d", i%50)
+			if customer != nil && customer(i) != "" {
+				name = customer(i)
+			}
+			add([]any{fmt.Sprintf("INV-%06d", i), "2025-09-01", name, fmt.Sprintf("Sales %d", i%10), "CASH", ""})
+		}
+	})
+	write("product sold", []any{"invoice no", "item", "quantity", "total cogs", "total price"}, func(add func(row []any)) {
+		for i := 0; i < invoices; i++ {
+			for j := 0; j < productsEach; j++ {
+				add([]any{fmt.Sprintf("INV-%06d", i), fmt.Sprintf("Product %d", j), 1 + j%3, "1000", "1500"})
+			}
+		}
+	})
+	file.DeleteSheet("Sheet1")
+
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		tb.Fatalf("write workbook: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkImportInvoices(b *testing.B) {
+	data := importWorkbook(b, 5000, 4, nil)
+	options := model.ImportOptions{Mode: model.ImportModeBestEffort, OnConflict: model.ImportConflictReplace}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		useCase := newTestInvoiceUseCase(b)
+		b.StartTimer()
+		result, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if result.Created != 5000 || len(result.Errors) != 0 {
+			b.Fatalf("created %d invoices with %d errors, first %+v", result.Created, len(result.Errors), result.Errors[0])
+		}
+	}
+}
+
+func newPersistState(atomic bool, invoices []entity.Invoice) *importState {
+	state := &importState{
+		invoiceSheet:   "invoice",
+		productSheet:   "product sold",
+		atomic:         atomic,
+		invoiceColumns: importColumns{model.ImportFieldInvoiceNo: "A"},
+		invoices:       map[string]int{},
+		written:        []string{},
+		invoiceRows:    map[string]int{},
+		rejectedRows:   map[string]int{},
+		replacing:      map[string]bool{},
+		skipped:        map[string]int{},
+		errors:         []model.ImportError{},
+	}
+	for i, invoice := range invoices {
+		state.invoices[invoice.InvoiceNo] = 0
+		state.invoiceRows[invoice.InvoiceNo] = i + 2
+	}
+	return state
+}
+
+// persistTestInvoices returns three invoices, the second of which the
+// database refuses.
+func persistTestInvoices() []entity.Invoice {
+	invoices := make([]entity.Invoice, 3)
+	for i := range invoices {
+		invoices[i] = entity.Invoice{
+			InvoiceNo:       fmt.Sprintf("INV-%d", i+1),
+			Date:            time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+			CustomerName:    "Customer 1",
+			SalespersonName: "Sales 1",
+			PaymentType:     "CASH",
+			Status:          model.InvoiceStatusIssued,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+	}
+	invoices[1].CustomerName = "Rejected Customer"
+	return invoices
+}
+
+func TestPersistInvoicesBestEffortKeepsGoodRows(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	invoices := persistTestInvoices()
+	state := newPersistState(false, invoices)
+
+	tx := useCase.DB.Begin()
+	defer tx.Rollback()
+	useCase.persistInvoices(tx, invoices, state)
+	if err := tx.Commit().Error; err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	if !slices.Equal(state.written, []string{"INV-1", "INV-3"}) {
+		t.Errorf("written = %v, want [INV-1 INV-3]", state.written)
+	}
+	if len(state.errors) != 1 {
+		t.Fatalf("errors = %+v, want one", state.errors)
+	}
+	if err := state.errors[0]; err.Code != model.ImportErrorSaveFailed || err.InvoiceNo != "INV-2" || err.Row != 3 {
+		t.Errorf("error = %+v, want SAVE_FAILED for INV-2 on row 3", err)
+	}
+	if _, rejected := state.rejectedRows["INV-2"]; !rejected {
+		t.Error("INV-2 is not recorded as rejected")
+	}
+
+	var stored []string
+	useCase.DB.Table("invoices").Order("invoice_no").Pluck("invoice_no", &stored)
+	if !slices.Equal(stored, []string{"INV-1", "INV-3"}) {
+		t.Errorf("stored invoices = %v, want [INV-1 INV-3]", stored)
+	}
+}
+
+func TestPersistInvoicesAtomicStopsAtFirstError(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	invoices := persistTestInvoices()
+	state := newPersistState(true, invoices)
+
+	tx := useCase.DB.Begin()
+	defer tx.Rollback()
+	useCase.persistInvoices(tx, invoices, state)
+
+	if !state.halted() {
+		t.Fatal("atomic import is not halted after a failed invoice")
+	}
+	if !slices.Equal(state.written, []string{"INV-1"}) {
+		t.Errorf("written = %v, want [INV-1]", state.written)
+	}
+	if len(state.errors) != 1 || state.errors[0].InvoiceNo != "INV-2" {
+		t.Errorf("errors = %+v, want one for INV-2", state.errors)
+	}
+}
+
+func TestImportInvoicesRejectedRow(t *testing.T) {
+	data := importWorkbook(t, 1200, 2, func(i int) string {
+		if i == 700 {
+			return "Rejected Customer"
+		}
+		return ""
+	})
+
+	tests := []struct {
+		mode       string
+		rolledBack bool
+		invoices   int64
+		products   int64
+	}{
+		{model.ImportModeAtomic, true, 0, 0},
+		{model.ImportModeBestEffort, false, 1199, 2398},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			useCase := newTestInvoiceUseCase(t)
+			if err := useCase.DB.Exec("INSERT INTO customers (code, name, name_key) VALUES ('CUST-REJECTED', 'Rejected Customer', 'rejected customer')").Error; err != nil {
+				t.Fatalf("create customer: %v", err)
+			}
+
+			options := model.ImportOptions{Mode: tt.mode, OnConflict: model.ImportConflictError}
+			result, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+			if err != nil {
+				t.Fatalf("ImportInvoices: %v", err)
+			}
+			if result.RolledBack != tt.rolledBack || int64(result.Created) != tt.invoices {
+				t.Errorf("rolled back %v with %d created, want %v with %d", result.RolledBack, result.Created, tt.rolledBack, tt.invoices)
+			}
+			if len(result.Errors) == 0 || result.Errors[0].Code != model.ImportErrorSaveFailed || result.Errors[0].InvoiceNo != "INV-000700" {
+				t.Errorf("errors = %+v, want SAVE_FAILED for INV-000700 first", result.Errors)
+			}
+
+			var invoices, products int64
+			useCase.DB.Table("invoices").Count(&invoices)
+			useCase.DB.Table("products").Count(&products)
+			if invoices != tt.invoices || products != tt.products {
+				t.Errorf("stored %d invoices and %d products, want %d and %d", invoices, products, tt.invoices, tt.products)
+			}
+		})
+	}
+}
+
+func TestImportInvoicesKeepsUnregisteredSalesperson(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	if err := useCase.DB.Exec("DELETE FROM salespersons WHERE name = 'Sales 1'").Error; err != nil {
+		t.Fatalf("delete salesperson: %v", err)
+	}
+
+	data := importWorkbook(t, 2, 1, nil)
+	options := model.ImportOptions{Mode: model.ImportModeAtomic, OnConflict: model.ImportConflictError}
+	result, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+	if err != nil {
+		t.Fatalf("ImportInvoices: %v", err)
+	}
+	if result.Created != 2 || len(result.Errors) != 0 {
+		t.Fatalf("created %d invoices with errors %+v, want 2 without", result.Created, result.Errors)
+	}
+
+	for _, invoice := range result.Invoices {
+		registered := invoice.SalespersonName == "Sales 0"
+		if (invoice.SalespersonID != nil) != registered {
+			t.Errorf("invoice %s of %s has salesperson_id %v", invoice.InvoiceNo, invoice.SalespersonName, invoice.SalespersonID)
+		}
+	}
+}
+
+func TestImportInvoicesReplacesEarlierImport(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	data := importWorkbook(t, 3, 2, nil)
+	options := model.ImportOptions{Mode: model.ImportModeAtomic, OnConflict: model.ImportConflictReplace}
+
+	first, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+	if err != nil {
+		t.Fatalf("first ImportInvoices: %v", err)
+	}
+	if first.Created != 3 || first.Updated != 0 || len(first.Errors) != 0 {
+		t.Fatalf("first import created %d and updated %d with errors %+v, want 3 created", first.Created, first.Updated, first.Errors)
+	}
+
+	if err := useCase.DB.Exec("INSERT INTO payments (invoice_no, date, amount, method, created_at, updated_at) VALUES ('INV-000001', '2025-09-02', 100, 'cash', ?, ?)",
+		time.Now(), time.Now()).Error; err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+
+	options.Mode = model.ImportModeBestEffort
+	second, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+	if err != nil {
+		t.Fatalf("second ImportInvoices: %v", err)
+	}
+	if second.Created != 0 || second.Updated != 2 {
+		t.Errorf("second import created %d and updated %d, want 0 and 2", second.Created, second.Updated)
+	}
+	if len(second.Errors) == 0 || second.Errors[0].Code != model.ImportErrorInvoiceNotEditable || second.Errors[0].InvoiceNo != "INV-000001" {
+		t.Errorf("errors = %+v, want INVOICE_NOT_EDITABLE for the paid INV-000001", second.Errors)
+	}
+
+	var invoices, products int64
+	useCase.DB.Table("invoices").Where("status = ?", model.InvoiceStatusIssued).Count(&invoices)
+	useCase.DB.Table("products").Count(&products)
+	if invoices != 3 || products != 6 {
+		t.Errorf("stored %d issued invoices and %d products, want 3 and 6", invoices, products)
+	}
+}
+
+func TestImportInvoicesRestoresReplacementWithoutProducts(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	data := importWorkbook(t, 3, 2, nil)
+	options := model.ImportOptions{Mode: model.ImportModeBestEffort, OnConflict: model.ImportConflictReplace}
+	if _, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil); err != nil {
+		t.Fatalf("first ImportInvoices: %v", err)
+	}
+
+	var before []string
+	useCase.DB.Table("invoices").Order("invoice_no").Pluck("updated_at", &before)
+	// Refuse the imported products of INV-000001 but not its stored ones.
+	for _, statement := range []string{
+		"CREATE TABLE stored_products AS SELECT id FROM products WHERE invoice_no = 'INV-000001'",
+		`CREATE TRIGGER refuse_products BEFORE INSERT ON products
+			WHEN NEW.invoice_no = 'INV-000001' AND NEW.id NOT IN (SELECT id FROM stored_products)
+			BEGIN SELECT RAISE(ABORT, 'refused'); END`,
+	} {
+		if err := useCase.DB.Exec(statement).Error; err != nil {
+			t.Fatalf("refuse products: %v", err)
+		}
+	}
+
+	result, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+	if err != nil {
+		t.Fatalf("second ImportInvoices: %v", err)
+	}
+	if result.Updated != 2 || len(result.Invoices) != 2 {
+		t.Errorf("updated %d invoices, listing %d, want 2", result.Updated, len(result.Invoices))
+	}
+	rejected := false
+	for _, importErr := range result.Errors {
+		if importErr.Code == model.ImportErrorSaveFailed && importErr.InvoiceNo == "INV-000001" && importErr.Sheet == "invoice" {
+			rejected = true
+		}
+	}
+	if !rejected {
+		t.Errorf("errors = %+v, want SAVE_FAILED for INV-000001 on the invoice sheet", result.Errors)
+	}
+
+	var after []string
+	useCase.DB.Table("invoices").Order("invoice_no").Pluck("updated_at", &after)
+	if before[1] != after[1] {
+		t.Errorf("INV-000001 updated_at = %s, want it restored to %s", after[1], before[1])
+	}
+	var products int64
+	useCase.DB.Table("products").Where("invoice_no = ?", "INV-000001").Count(&products)
+	if products != 2 {
+		t.Errorf("INV-000001 has %d products, want its 2 stored ones back", products)
+	}
+}
+
+func TestImportInvoicesKeepsUnregisteredCustomer(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	data := importWorkbook(t, 2, 1, func(i int) string {
+		if i == 1 {
+			return "Walk-in Customer"
+		}
+		return ""
+	})
+
+	options := model.ImportOptions{Mode: model.ImportModeAtomic, OnConflict: model.ImportConflictError}
+	result, err := useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+	if err != nil {
+		t.Fatalf("ImportInvoices: %v", err)
+	}
+	if result.Created != 2 || len(result.Errors) != 0 {
+		t.Fatalf("created %d invoices with errors %+v, want 2 without", result.Created, result.Errors)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != model.ImportErrorUnknownCustomer || result.Warnings[0].InvoiceNo != "INV-000001" {
+		t.Errorf("warnings = %+v, want UNKNOWN_CUSTOMER for INV-000001", result.Warnings)
+	}
+
+	for _, invoice := range result.Invoices {
+		registered := invoice.CustomerName == "Customer 0"
+		if (invoice.CustomerID != nil) != registered {
+			t.Errorf("invoice %s of %s has customer_id %v", invoice.InvoiceNo, invoice.CustomerName, invoice.CustomerID)
+		}
+	}
+
+	options.CustomerMatch = model.CustomerMatchCode
+	options.OnConflict = model.ImportConflictReplace
+	result, err = useCase.ImportInvoices(context.Background(), bytes.NewReader(data), int64(len(data)), options, nil)
+	if err != nil {
+		t.Fatalf("ImportInvoices by code: %v", err)
+	}
+	if !result.RolledBack || len(result.Errors) == 0 || result.Errors[0].Code != model.ImportErrorUnknownCustomer {
+		t.Errorf("rolled back %v with errors %+v, want UNKNOWN_CUSTOMER errors for codes matching no customer", result.RolledBack, result.Errors)
+	}
+}
+
+func TestDetectCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want rune
+	}{
+		{"comma", "invoice no,date,customer\n1,2025-09-01,A", ','},
+		{"semicolon", "invoice no;date;customer\n1;2025-09-01;A", ';'},
+		{"tab", "invoice no\tdate\tcustomer\n1\t2025-09-01\tA", '\t'},
+		{"pipe", "invoice no|date|customer", '|'},
+		{"quoted semicolons", `"no;1","date;2",customer` + "\n", ','},
+		{"quoted commas", `"invoice no, as printed";date;customer`, ';'},
+		{"decimal commas after the header", "invoice no;total\n1;1,5\n2;2,5\n3;3,5", ';'},
+		{"crlf", "invoice no;date\r\n1;2", ';'},
+		{"single column", "invoice no\n1", ','},
+		{"empty", "", ','},
+	}
+	for _, tt := range tests {
+		if got := detectCSVDelimiter([]byte(tt.text)); got != tt.want {
+			t.Errorf("%s: detectCSVDelimiter = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// csvZipFile stores data in a zip archive and returns its entry, as the CSV
+// importer reads it.
+func csvZipFile(t *testing.T, data []byte) *zip.File {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, err := archive.Create("invoices.csv")
+	if err != nil {
+		t.Fatalf("create zip entry: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("write zip entry: %v", err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	return reader.File[0]
+}
+
+func TestReadCSVFileDecodesEncodings(t *testing.T) {
+	mustEncode := func(data []byte, err error) []byte {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		return data
+	}
+	windows1252 := func(text string) []byte {
+		return mustEncode(charmap.Windows1252.NewEncoder().Bytes([]byte(text)))
+	}
+	utf16 := func(text string) []byte {
+		return mustEncode(textunicode.UTF16(textunicode.LittleEndian, textunicode.UseBOM).NewEncoder().Bytes([]byte(text)))
+	}
+	// ASCII rows filling more than the delimiter detection window, followed
+	// by the first byte that is not UTF-8.
+	late := bytes.Repeat([]byte("1;Cafe;Speaker\n"), csvHeadSize/15+10)
+
+	tests := []struct {
+		name string
+		data []byte
+		want [][]string
+	}{
+		{"utf-8", []byte("no,customer\n1,Café"), [][]string{{"no", "customer"}, {"1", "Café"}}},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "no;customer\n1;Café"...), [][]string{{"no", "customer"}, {"1", "Café"}}},
+		{"utf-16 bom", utf16("no\tcustomer\n1\tCafé"), [][]string{{"no", "customer"}, {"1", "Café"}}},
+		{"windows-1252", windows1252("no;customer;notes\n1;Café;“rush”"), [][]string{{"no", "customer", "notes"}, {"1", "Café", "“rush”"}}},
+		{"quoted delimiters", []byte("no;customer\n1;\"Maju; Jaya\"\n"), [][]string{{"no", "customer"}, {"1", "Maju; Jaya"}}},
+		{"windows-1252 late", append(append([]byte("no;customer;item\n"), late...), windows1252("2;Señor;Speaker")...), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readCSVFile(csvZipFile(t, tt.data))
+			if err != nil {
+				t.Fatalf("readCSVFile: %v", err)
+			}
+			defer rows.Close()
+
+			var got [][]string
+			for rows.Next() {
+				row, _, err := rows.Row()
+				if err != nil {
+					t.Fatalf("read row %d: %v", len(got)+1, err)
+				}
+				got = append(got, row)
+			}
+
+			if tt.want == nil {
+				// Only the start and the end of the long file are checked.
+				if last := got[len(got)-1]; !slices.Equal(got[0], []string{"no", "customer", "item"}) || !slices.Equal(last, []string{"2", "Señor", "Speaker"}) {
+					t.Errorf("rows start with %q and end with %q", got[0], last)
+				}
+				return
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"golang-technical-challenge/internal/repository"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type InvoicePDFUseCase struct {
+	DB                *gorm.DB
+	Log               *logrus.Logger
+	Validate          *validator.Validate
+	InvoiceRepository *repository.InvoiceRepository
+	Template          *model.InvoicePDFTemplate
+}
+
+func NewInvoicePDFUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, invoiceRepository *repository.InvoiceRepository,
+	template *model.InvoicePDFTemplate) *InvoicePDFUseCase {
+	return &InvoicePDFUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		InvoiceRepository: invoiceRepository,
+		Template:          template,
+	}
+}
+
+// DefaultInvoicePDFTemplate is the layout used when no template file is
+// configured, and the base that a template file overrides.
+func DefaultInvoicePDFTemplate() *model.InvoicePDFTemplate {
+	return &model.InvoicePDFTemplate{
+		PageSize:           "A4",
+		FontFamily:         "Helvetica",
+		AccentColor:        "#1F4E79",
+		Title:              "INVOICE",
+		ThousandsSeparator: ",",
+		DecimalSeparator:   ".",
+		DateFormat:         "02 Jan 2006",
+		Labels: model.InvoicePDFLabels{
+			InvoiceNo:   "Invoice No",
+			Date:        "Date",
+			Customer:    "Customer",
+			Salesperson: "Salesperson",
+			PaymentType: "Payment Type",
+			Notes:       "Notes",
+			Item:        "Item",
+			Quantity:    "Qty",
+			UnitPrice:   "Unit Price",
+			LineTotal:   "Line Total",
+			TotalItems:  "Total Items",
+			GrandTotal:  "Grand Total",
+			Page:        "Page",
+			Draft:       "DRAFT",
+			Void:        "VOID",
+		},
+	}
+}
+
+func (c *InvoicePDFUseCase) Render(ctx context.Context, request *model.GetInvoiceRequest) (*model.InvoicePDF, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Warn("Invalid invoice PDF request")
+		return nil, fiber.ErrBadRequest
+	}
+
+	invoice := new(entity.Invoice)
+	if err := c.InvoiceRepository.FindByInvoiceNo(c.DB.WithContext(ctx), invoice, request.InvoiceNo); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to fetch invoice")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	content, err := renderInvoicePDF(c.Template, invoice)
+	if err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to render invoice PDF")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.InvoicePDF{
+		FileName: "invoice-" + unsafeFileNameChars.ReplaceAllString(invoice.InvoiceNo, "_") + ".pdf",
+		Content:  content,
+	}, nil
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+const (
+	pdfMargin     = 15.0
+	pdfLineHeight = 6.0
+)
+
+// invoicePDF draws one invoice with the core PDF fonts, which only cover
+// Windows-1252; text is translated to it and other characters are dropped.
+type invoicePDF struct {
+	pdf      *fpdf.Fpdf
+	template *model.InvoicePDFTemplate
+	tr       func(string) string
+	accent   [3]int
+	columns  []pdfColumn
+}
+
+type pdfColumn struct {
+	label string
+	width float64
+	align string
+}
+
+func renderInvoicePDF(template *model.InvoicePDFTemplate, invoice *entity.Invoice) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", template.PageSize, "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin+5)
+	pdf.AliasNbPages("")
+
+	r := &invoicePDF{
+		pdf:      pdf,
+		template: template,
+		tr:       pdf.UnicodeTranslatorFromDescriptor(""),
+		accent:   hexColor(template.AccentColor),
+	}
+
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pdfMargin
+	labels := template.Labels
+	r.columns = []pdfColumn{
+		{"#", 10, "C"},
+		{labels.Item, contentWidth - 10 - 18 - 2*34, "L"},
+		{labels.Quantity, 18, "R"},
+		{labels.UnitPrice, 34, "R"},
+		{labels.LineTotal, 34, "R"},
+	}
+
+	pdf.SetFooterFunc(r.footer)
+	pdf.AddPage()
+	r.header(invoice, contentWidth)
+	r.parties(invoice, contentWidth)
+	r.lines(invoice)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *invoicePDF) header(invoice *entity.Invoice, contentWidth float64) {
+	pdf, template := r.pdf, r.template
+	company := template.Company
+	top := pdf.GetY()
+
+	textX := pdfMargin
+	if company.Logo != "" {
+		pdf.ImageOptions(company.Logo, pdfMargin, top, 0, 18, false, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+		textX += 32
+	}
+
+	pdf.SetXY(textX, top)
+	pdf.SetFont(template.FontFamily, "B", 14)
+	pdf.SetTextColor(r.accent[0], r.accent[1], r.accent[2])
+	pdf.CellFormat(0, 7, r.tr(company.Name), "", 2, "L", false, 0, "")
+	pdf.SetFont(template.FontFamily, "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	details := append([]string{}, company.Address...)
+	for _, contact := range []string{company.Phone, company.Email} {
+		if contact != "" {
+			details = append(details, contact)
+		}
+	}
+	for _, line := range details {
+		pdf.SetX(textX)
+		pdf.CellFormat(0, 4.5, r.tr(line), "", 2, "L", false, 0, "")
+	}
+	leftBottom := pdf.GetY()
+
+	labels := template.Labels
+	pdf.SetXY(pdfMargin, top)
+	pdf.SetFont(template.FontFamily, "B", 20)
+	pdf.SetTextColor(r.accent[0], r.accent[1], r.accent[2])
+	pdf.CellFormat(contentWidth, 9, r.tr(template.Title), "", 2, "R", false, 0, "")
+	pdf.SetFont(template.FontFamily, "", 10)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(contentWidth, 5, r.tr(labels.InvoiceNo+": "+invoice.InvoiceNo), "", 2, "R", false, 0, "")
+	pdf.CellFormat(contentWidth, 5, r.tr(labels.Date+": "+invoice.Date.Format(template.DateFormat)), "", 2, "R", false, 0, "")
+	if status := r.status(invoice); status != "" {
+		pdf.SetFont(template.FontFamily, "B", 12)
+		pdf.SetTextColor(192, 0, 0)
+		pdf.CellFormat(contentWidth, 7, r.tr(status), "", 2, "R", false, 0, "")
+		pdf.SetFont(template.FontFamily, "", 10)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	y := max(leftBottom, pdf.GetY(), top+18) + 4
+	pdf.SetDrawColor(r.accent[0], r.accent[1], r.accent[2])
+	pdf.SetLineWidth(0.6)
+	pdf.Line(pdfMargin, y, pdfMargin+contentWidth, y)
+	pdf.SetY(y + 5)
+}
+
+// status returns the label printed on an invoice that is not a valid bill,
+// a draft or a void invoice, and "" for the others.
+func (r *invoicePDF) status(invoice *entity.Invoice) string {
+	switch invoice.Status {
+	case model.InvoiceStatusDraft:
+		return r.template.Labels.Draft
+	case model.InvoiceStatusVoid:
+		return r.template.Labels.Void
+	}
+	return ""
+}
+
+func (r *invoicePDF) parties(invoice *entity.Invoice, contentWidth float64) {
+	pdf, template := r.pdf, r.template
+	labels := template.Labels
+
+	for _, field := range [][2]string{
+		{labels.Customer, invoice.CustomerName},
+		{labels.Salesperson, invoice.SalespersonName},
+		{labels.PaymentType, invoice.PaymentType},
+	} {
+		pdf.SetFont(template.FontFamily, "B", 10)
+		pdf.CellFormat(35, pdfLineHeight, r.tr(field[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont(template.FontFamily, "", 10)
+		pdf.CellFormat(contentWidth-35, pdfLineHeight, r.tr(field[1]), "", 1, "L", false, 0, "")
+	}
+
+	if invoice.Notes != nil && strings.TrimSpace(*invoice.Notes) != "" {
+		pdf.SetFont(template.FontFamily, "B", 10)
+		pdf.CellFormat(35, pdfLineHeight, r.tr(labels.Notes), "", 0, "L", false, 0, "")
+		pdf.SetFont(template.FontFamily, "", 10)
+		pdf.MultiCell(contentWidth-35, pdfLineHeight, r.tr(*invoice.Notes), "", "L", false)
+	}
+	pdf.Ln(5)
+}
+
+// lines draws the product table followed by the totals. Item names wrap, and
+// the table header is repeated when a row does not fit on the page.
+func (r *invoicePDF) lines(invoice *entity.Invoice) {
+	pdf, template := r.pdf, r.template
+	_, pageHeight := pdf.GetPageSize()
+	_, breakMargin := pdf.GetAutoPageBreak()
+
+	r.tableHeader()
+	pdf.SetFont(template.FontFamily, "", 10)
+	pdf.SetDrawColor(210, 210, 210)
+	pdf.SetLineWidth(0.2)
+
+	totalQuantity := 0
+	grandTotal := decimal.Zero
+	for i, product := range invoice.Products {
+		// The grand total adds up the line totals as printed.
+		lineTotal := converter.RoundAmount(product.Revenue())
+		totalQuantity += product.Quantity
+		grandTotal = grandTotal.Add(lineTotal)
+
+		item := pdf.SplitText(r.tr(product.ItemName), r.columns[1].width-2)
+		height := float64(max(len(item), 1)) * pdfLineHeight
+		if pdf.GetY()+height > pageHeight-breakMargin {
+			pdf.AddPage()
+			r.tableHeader()
+			pdf.SetFont(template.FontFamily, "", 10)
+			pdf.SetDrawColor(210, 210, 210)
+			pdf.SetLineWidth(0.2)
+		}
+
+		fill := i%2 == 1
+		pdf.SetFillColor(245, 245, 245)
+		values := []string{
+			strconv.Itoa(i + 1),
+			"",
+			strconv.Itoa(product.Quantity),
+			r.amount(product.TotalPrice),
+			r.amount(lineTotal),
+		}
+		x, y := pdf.GetXY()
+		for j, column := range r.columns {
+			pdf.SetXY(x, y)
+			if j == 1 {
+				pdf.CellFormat(column.width, height, "", "B", 0, "L", fill, 0, "")
+				for k, line := range item {
+					pdf.SetXY(x, y+float64(k)*pdfLineHeight)
+					pdf.CellFormat(column.width, pdfLineHeight, line, "", 0, "L", false, 0, "")
+				}
+			} else {
+				pdf.CellFormat(column.width, height, r.tr(values[j]), "B", 0, column.align, fill, 0, "")
+			}
+			x += column.width
+		}
+		pdf.SetXY(pdfMargin, y+height)
+	}
+
+	labelWidth := r.columns[0].width + r.columns[1].width + r.columns[2].width + r.columns[3].width
+	valueWidth := r.columns[4].width
+	if pdf.GetY()+2*pdfLineHeight+2 > pageHeight-breakMargin {
+		pdf.AddPage()
+	}
+	pdf.Ln(2)
+	pdf.SetFont(template.FontFamily, "", 10)
+	pdf.CellFormat(labelWidth, pdfLineHeight, r.tr(template.Labels.TotalItems), "", 0, "R", false, 0, "")
+	pdf.CellFormat(valueWidth, pdfLineHeight, strconv.Itoa(totalQuantity), "", 1, "R", false, 0, "")
+	pdf.SetFont(template.FontFamily, "B", 11)
+	pdf.SetTextColor(r.accent[0], r.accent[1], r.accent[2])
+	pdf.CellFormat(labelWidth, pdfLineHeight+1, r.tr(template.Labels.GrandTotal), "", 0, "R", false, 0, "")
+	pdf.CellFormat(valueWidth, pdfLineHeight+1, r.tr(r.money(grandTotal)), "T", 1, "R", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
+
+func (r *invoicePDF) tableHeader() {
+	pdf := r.pdf
+	pdf.SetFont(r.template.FontFamily, "B", 10)
+	pdf.SetFillColor(r.accent[0], r.accent[1], r.accent[2])
+	pdf.SetTextColor(255, 255, 255)
+	for _, column := range r.columns {
+		pdf.CellFormat(column.width, pdfLineHeight+1, r.tr(column.label), "", 0, column.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetTextColor(0, 0, 0)
+}
+
+func (r *invoicePDF) footer() {
+	pdf := r.pdf
+	pdf.SetY(-pdfMargin - 2)
+	pdf.SetFont(r.template.FontFamily, "", 8)
+	pdf.SetTextColor(120, 120, 120)
+	if r.template.Footer != "" {
+		pdf.CellFormat(0, 4, r.tr(r.template.Footer), "", 2, "C", false, 0, "")
+	}
+	pdf.CellFormat(0, 4, r.tr(fmt.Sprintf("%s %d / {nb}", r.template.Labels.Page, pdf.PageNo())), "", 0, "C", false, 0, "")
+}
+
+// amount formats value with two decimals and the template's separators.
+func (r *invoicePDF) amount(value decimal.Decimal) string {
+	return converter.FormatAmount(value, r.template.ThousandsSeparator, r.template.DecimalSeparator)
+}
+
+func (r *invoicePDF) money(value decimal.Decimal) string {
+	if r.template.Currency == "" {
+		return r.amount(value)
+	}
+	return r.template.Currency + " " + r.amount(value)
+}
+
+// hexColor parses a validated "#RRGGBB" or "#RGB" color.
+func hexColor(value string) [3]int {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+	var rgb [3]int
+	for i := range rgb {
+		component, _ := strconv.ParseUint(value[2*i:2*i+2], 16, 8)
+		rgb[i] = int(component)
+	}
+	return rgb
+}
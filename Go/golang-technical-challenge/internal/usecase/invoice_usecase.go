@@ -2,13 +2,15 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"golang-technical-challenge/internal/entity"
 	"golang-technical-challenge/internal/model"
 	"golang-technical-challenge/internal/model/converter"
 	"golang-technical-challenge/internal/repository"
-	"mime/multipart"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,294 +18,236 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
-	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
 
 type InvoiceUseCase struct {
-	DB                *gorm.DB
-	Log               *logrus.Logger
-	Validate          *validator.Validate
-	InvoiceRepository *repository.InvoiceRepository
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	InvoiceRepository     *repository.InvoiceRepository
+	CustomerRepository    *repository.CustomerRepository
+	SalespersonRepository *repository.SalespersonRepository
 }
 
 func NewInvoiceUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, invoiceRepository *repository.InvoiceRepository,
+	customerRepository *repository.CustomerRepository, salespersonRepository *repository.SalespersonRepository,
 ) *InvoiceUseCase {
 	return &InvoiceUseCase{
-		DB:                db,
-		Log:               logger,
-		Validate:          validate,
-		InvoiceRepository: invoiceRepository,
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		InvoiceRepository:     invoiceRepository,
+		CustomerRepository:    customerRepository,
+		SalespersonRepository: salespersonRepository,
 	}
 }
 
-func parseDateFromCell(raw string) (time.Time, error) {
-	raw = strings.TrimSpace(raw)
-	dateFormats := []string{
-		"2006-01-02",                                           // ISO
-		"02/01/2006", "01/02/2006", "02-01-2006", "01-02-2006", // DMY and MDY with 4-digit year
-		"02/01/06", "01/02/06", "02-01-06", "01-02-06", // DMY and MDY with 2-digit year
-		"2/1/06", "1/2/06", "2-1-06", "1-2-06", // Single-digit day/month
+// checkInvoiceFilterRanges rejects a validated filter whose date or total
+// range is empty because its bounds are the wrong way round.
+func checkInvoiceFilterRanges(filter *model.InvoiceFilter) error {
+	if filter.DateFrom != "" && filter.DateTo != "" && filter.DateTo < filter.DateFrom {
+		return fiber.NewError(fiber.StatusBadRequest, "date_to must not be before date_from")
 	}
-
-	for _, layout := range dateFormats {
-		if t, err := time.Parse(layout, raw); err == nil {
-			return t, nil
-		}
-	}
-	if serial, err := strconv.ParseFloat(raw, 64); err == nil {
-		base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
-		return base.AddDate(0, 0, int(serial)), nil
+	if filter.MinTotal != "" && filter.MaxTotal != "" &&
+		decimal.RequireFromString(filter.MaxTotal).LessThan(decimal.RequireFromString(filter.MinTotal)) {
+		return fiber.NewError(fiber.StatusBadRequest, "max_total must not be less than min_total")
 	}
-	return time.Time{}, fmt.Errorf("unrecognized date format: %s", raw)
+	return nil
 }
 
-func (c *InvoiceUseCase) ImportInvoices(ctx context.Context, file *multipart.FileHeader) (any, error) {
-	f, err := file.Open()
-	if err != nil {
-		c.Log.WithError(err).Error("Failed to open uploaded file")
+func (c *InvoiceUseCase) GetInvoices(ctx context.Context, request *model.SearchInvoiceRequest) (*model.InvoiceListResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid invoice search request")
+		return nil, fiber.NewError(fiber.StatusBadRequest, validationMessage("Invalid invoice filters", err))
+	}
+	filter := &request.InvoiceFilter
+	if err := checkInvoiceFilterRanges(filter); err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	xlsx, err := excelize.OpenReader(f)
+	sortParam := request.Sort
+	var cursor *model.InvoiceCursor
+	if request.Cursor != "" {
+		var err error
+		if cursor, err = decodeInvoiceCursor(request.Cursor); err != nil {
+			c.Log.WithError(err).Warn("Invalid invoice cursor")
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
+		}
+		if sortParam == "" {
+			sortParam = cursor.Sort
+		}
+	}
+	sort, err := parseInvoiceSort(sortParam)
 	if err != nil {
-		c.Log.WithError(err).Error("Failed to parse XLSX file")
 		return nil, err
 	}
-	defer xlsx.Close()
+	if cursor != nil && formatInvoiceSort(sort) != cursor.Sort {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("The cursor belongs to a listing sorted by %s", cursor.Sort))
+	}
 
-	invoiceRows, err := xlsx.GetRows("invoice")
-	if err != nil {
-		c.Log.WithError(err).Error("Failed to read 'invoice' sheet")
-		return nil, fmt.Errorf("cannot read invoice sheet: %w", err)
+	page, size := request.Page, request.Size
+	if page <= 0 {
+		page = 1
 	}
-	productRows, err := xlsx.GetRows("product sold")
-	if err != nil {
-		c.Log.WithError(err).Error("Failed to read 'product sold' sheet")
-		return nil, fmt.Errorf("cannot read product sold sheet: %w", err)
+	if size <= 0 {
+		size = 10
 	}
+	offset := (page - 1) * size
 
-	errors := []model.ImportError{}
-	invoiceMap := map[string]*entity.Invoice{}
+	tx := c.DB.WithContext(ctx)
 
-	c.parseInvoiceRows(ctx, xlsx, invoiceRows, invoiceMap, &errors)
-	c.parseProductRows(productRows, invoiceMap, &errors)
+	totalItems, err := c.InvoiceRepository.CountInvoices(tx, filter)
+	if err != nil {
+		c.Log.WithError(err).WithField("filter", filter).Error("Failed to count invoices")
+		return nil, fiber.ErrInternalServerError
+	}
 
-	tx := c.DB.WithContext(ctx).Begin()
-	for _, invoice := range invoiceMap {
-		if len(invoice.Products) == 0 {
-			errors = append(errors, model.ImportError{InvoiceNo: invoice.InvoiceNo, Message: "No valid products for this invoice"})
-			continue
-		}
-		if err := c.InvoiceRepository.Create(tx, invoice); err != nil {
-			errors = append(errors, model.ImportError{InvoiceNo: invoice.InvoiceNo, Message: "Failed to save invoice"})
+	var invoices []entity.Invoice
+	hasNext, hasPrev := false, false
+	if cursor == nil {
+		invoices, err = c.InvoiceRepository.FindInvoices(tx, filter, sort, size, offset)
+		hasNext = int64(offset+len(invoices)) < totalItems
+		hasPrev = offset > 0
+	} else {
+		// One extra invoice tells whether there is another page beyond this one.
+		invoices, err = c.InvoiceRepository.FindInvoicesFrom(tx, filter, sort, cursor, size+1)
+		more := len(invoices) > size
+		if more && cursor.Before {
+			invoices = invoices[1:]
+		} else if more {
+			invoices = invoices[:size]
 		}
+		// The cursor's own invoice lies on the side the page was read away from.
+		hasNext = more || cursor.Before
+		hasPrev = more || !cursor.Before
 	}
-	tx.Commit()
-
-	if len(errors) > 0 {
-		c.Log.WithField("error_count", len(errors)).Warn("Import completed with errors")
-		return errors, nil
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
 	}
-
-	invoiceNos := make([]string, 0, len(invoiceMap))
-	for _, inv := range invoiceMap {
-		invoiceNos = append(invoiceNos, inv.InvoiceNo)
+	if err != nil {
+		c.Log.WithError(err).WithField("filter", filter).Error("Failed to fetch invoices")
+		return nil, fiber.ErrInternalServerError
 	}
 
-	invoices, err := c.InvoiceRepository.FindInvoicesByNumbers(c.DB.WithContext(ctx), invoiceNos)
+	totalProfit, totalCash, err := c.InvoiceRepository.GetSummary(tx, filter)
 	if err != nil {
-		c.Log.WithError(err).Error("Failed to retrieve imported invoices")
-		return nil, err
+		c.Log.WithError(err).WithField("filter", filter).Error("Failed to calculate invoice summary")
+		return nil, fiber.ErrInternalServerError
 	}
 
-	totalProfit := decimal.Zero
-	totalCash := decimal.Zero
-	for _, inv := range invoices {
-		for _, p := range inv.Products {
-			cost := p.TotalCost.Mul(decimal.NewFromInt(int64(p.Quantity)))
-			price := p.TotalPrice.Mul(decimal.NewFromInt(int64(p.Quantity)))
-			totalProfit = totalProfit.Add(price.Sub(cost))
-			if inv.PaymentType == "CASH" {
-				totalCash = totalCash.Add(price)
-			}
-		}
+	totalCollected, err := c.InvoiceRepository.GetCollections(tx, filter)
+	if err != nil {
+		c.Log.WithError(err).WithField("filter", filter).Error("Failed to calculate credit collections")
+		return nil, fiber.ErrInternalServerError
 	}
 
 	invoiceResponses := converter.InvoicesToResponseList(invoices)
-	totalItems := int64(len(invoices))
-
-	return &model.InvoiceListResponse{
-		Invoices:    invoiceResponses,
-		TotalProfit: totalProfit.StringFixed(2),
-		TotalCash:   totalCash.StringFixed(2),
-		Paging: model.PageMetadata{
-			Page:      1,
-			Size:      len(invoices),
-			TotalItem: totalItems,
-			TotalPage: 1,
-		},
-	}, nil
-}
-
-func (c *InvoiceUseCase) parseInvoiceRows(ctx context.Context, xlsx *excelize.File, rows [][]string, invoiceMap map[string]*entity.Invoice, errors *[]model.ImportError) {
-	for i, row := range rows[1:] {
-		rowNum := i + 2
-		if len(row) < 5 {
-			*errors = append(*errors, model.ImportError{InvoiceNo: fmt.Sprintf("row %d", rowNum), Message: "Missing invoice fields"})
-			continue
-		}
-
-		invoiceNo := strings.TrimSpace(row[0])
-		customer := row[2]
-		sales := row[3]
-		paymentType := strings.ToUpper(row[4])
-		if paymentType != "CASH" && paymentType != "CREDIT" {
-			*errors = append(*errors, model.ImportError{InvoiceNo: invoiceNo, Message: "Invalid payment type"})
-			continue
-		}
+	totalPages := (totalItems + int64(size) - 1) / int64(size)
 
-		notes := ""
-		if len(row) > 5 {
-			notes = row[5]
+	paging := model.PageMetadata{
+		Page:      page,
+		Size:      size,
+		TotalItem: totalItems,
+		TotalPage: totalPages,
+	}
+	if cursor != nil {
+		paging.Page = 0
+	}
+	if len(invoices) > 0 {
+		if hasNext {
+			paging.NextCursor = c.encodeInvoiceCursor(&invoices[len(invoices)-1], sort, false)
 		}
-
-		dateCell := fmt.Sprintf("B%d", rowNum)
-		dateStr, _ := xlsx.GetCellValue("invoice", dateCell)
-		parsedDate, err := parseDateFromCell(dateStr)
-		if err != nil {
-			c.Log.WithFields(logrus.Fields{
-				"row":       rowNum,
-				"invoiceNo": invoiceNo,
-				"date":      dateStr,
-			}).Warn("Invalid date format")
-			*errors = append(*errors, model.ImportError{InvoiceNo: invoiceNo, Message: "Invalid date format"})
-			continue
+		if hasPrev {
+			paging.PrevCursor = c.encodeInvoiceCursor(&invoices[0], sort, true)
 		}
+	}
 
-		if invoiceNo == "" || customer == "" || sales == "" || paymentType == "" {
-			c.Log.WithFields(logrus.Fields{
-				"row":       rowNum,
-				"invoiceNo": invoiceNo,
-			}).Warn("Required invoice fields missing")
-			*errors = append(*errors, model.ImportError{InvoiceNo: invoiceNo, Message: "Required invoice fields are missing"})
-			continue
-		}
+	return &model.InvoiceListResponse{
+		Invoices:       invoiceResponses,
+		TotalProfit:    totalProfit,
+		TotalCash:      totalCash,
+		TotalCollected: totalCollected,
+		Paging:         paging,
+	}, nil
+}
 
-		existing := new(entity.Invoice)
-		if err := c.InvoiceRepository.FindByInvoiceNo(c.DB.WithContext(ctx), existing, invoiceNo); err == nil {
-			c.Log.WithField("invoice_no", invoiceNo).Warn("Duplicate invoice")
-			*errors = append(*errors, model.ImportError{InvoiceNo: invoiceNo, Message: "Duplicate invoice"})
-			continue
-		}
+// encodeInvoiceCursor returns an opaque token for the page after invoice,
+// or before it.
+func (c *InvoiceUseCase) encodeInvoiceCursor(invoice *entity.Invoice, sort []model.SortField, before bool) string {
+	data, _ := json.Marshal(model.InvoiceCursor{
+		Sort:   formatInvoiceSort(sort),
+		Values: c.InvoiceRepository.CursorValues(invoice, sort),
+		Before: before,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
 
-		invoiceMap[invoiceNo] = &entity.Invoice{
-			InvoiceNo:       invoiceNo,
-			Date:            parsedDate,
-			CustomerName:    customer,
-			SalespersonName: sales,
-			PaymentType:     paymentType,
-			Notes:           &notes,
-			Products:        []entity.Product{},
-			CreatedAt:       time.Now(),
-			UpdatedAt:       time.Now(),
-		}
+func decodeInvoiceCursor(token string) (*model.InvoiceCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	cursor := new(model.InvoiceCursor)
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, err
 	}
+	return cursor, nil
 }
 
-func (c *InvoiceUseCase) parseProductRows(rows [][]string, invoiceMap map[string]*entity.Invoice, errors *[]model.ImportError) {
-	for i, row := range rows[1:] {
-		rowNum := i + 2
-		if len(row) < 5 {
-			*errors = append(*errors, model.ImportError{InvoiceNo: fmt.Sprintf("row %d", rowNum), Message: "Missing product fields"})
-			continue
+// formatInvoiceSort writes sort back in the form parseInvoiceSort reads.
+func formatInvoiceSort(sort []model.SortField) string {
+	keys := make([]string, len(sort))
+	for i, field := range sort {
+		keys[i] = field.Key
+		if field.Desc {
+			keys[i] = "-" + field.Key
 		}
+	}
+	return strings.Join(keys, ",")
+}
 
-		invoiceNo := strings.TrimSpace(row[0])
-		item := row[1]
-		qtyStr := row[2]
-		costStr := row[3]
-		priceStr := row[4]
-
-		invoice, ok := invoiceMap[invoiceNo]
-		if !ok {
-			c.Log.WithFields(logrus.Fields{
-				"row":       rowNum,
-				"invoiceNo": invoiceNo,
-			}).Warn("Product refers to unknown invoice")
-			*errors = append(*errors, model.ImportError{InvoiceNo: invoiceNo, Message: "Product refers to unknown invoice"})
-			continue
+// parseInvoiceSort reads a sort parameter such as "-date,customer_name",
+// falling back to model.DefaultInvoiceSort when it is empty.
+func parseInvoiceSort(value string) ([]model.SortField, error) {
+	if strings.TrimSpace(value) == "" {
+		value = model.DefaultInvoiceSort
+	}
+
+	var fields []model.SortField
+	seen := map[string]bool{}
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		field := model.SortField{Key: strings.TrimPrefix(key, "-"), Desc: strings.HasPrefix(key, "-")}
+		if !slices.Contains(model.InvoiceSortKeys, field.Key) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid sort key %q, sort by %s, optionally prefixed with - for descending order",
+				key, strings.Join(model.InvoiceSortKeys, ", ")))
 		}
-
-		qty, err1 := strconv.Atoi(qtyStr)
-		cost, err2 := decimal.NewFromString(costStr)
-		price, err3 := decimal.NewFromString(priceStr)
-		if err1 != nil || err2 != nil || err3 != nil {
-			c.Log.WithFields(logrus.Fields{
-				"row":       rowNum,
-				"invoiceNo": invoiceNo,
-				"qty":       qtyStr,
-				"cost":      costStr,
-				"price":     priceStr,
-			}).Warn("Invalid product values")
-			*errors = append(*errors, model.ImportError{InvoiceNo: invoiceNo, Message: "Invalid product values"})
-			continue
+		if seen[field.Key] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Sort key %q is given more than once", field.Key))
 		}
-
-		invoice.Products = append(invoice.Products, entity.Product{
-			InvoiceNo:  invoiceNo,
-			ItemName:   item,
-			Quantity:   qty,
-			TotalCost:  cost,
-			TotalPrice: price,
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
-		})
+		seen[field.Key] = true
+		fields = append(fields, field)
 	}
+	return fields, nil
 }
 
-func (c *InvoiceUseCase) GetInvoices(ctx context.Context, date string, page, size int) (*model.InvoiceListResponse, error) {
-	if date == "" {
-		return nil, fiber.NewError(fiber.StatusBadRequest, "date parameter is required")
-	}
-
-	if page <= 0 {
-		page = 1
-	}
-	if size <= 0 {
-		size = 10
-	}
-	offset := (page - 1) * size
-
-	tx := c.DB.WithContext(ctx)
-
-	invoices, totalItems, err := c.InvoiceRepository.FindInvoicesByDate(tx, date, size, offset)
-	if err != nil {
-		c.Log.WithError(err).WithField("date", date).Error("Failed to fetch invoices")
-		return nil, fiber.ErrInternalServerError
+func (c *InvoiceUseCase) Get(ctx context.Context, request *model.GetInvoiceRequest) (*model.InvoiceResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Warn("Invalid get invoice request")
+		return nil, fiber.ErrBadRequest
 	}
 
-	totalProfit, totalCash, err := c.InvoiceRepository.GetSummaryByDate(tx, date)
-	if err != nil {
-		c.Log.WithError(err).WithField("date", date).Error("Failed to calculate invoice summary")
+	invoice := new(entity.Invoice)
+	if err := c.InvoiceRepository.FindByInvoiceNo(c.DB.WithContext(ctx), invoice, request.InvoiceNo); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to fetch invoice")
 		return nil, fiber.ErrInternalServerError
 	}
 
-	invoiceResponses := converter.InvoicesToResponseList(invoices)
-	totalPages := (totalItems + int64(size) - 1) / int64(size)
-
-	return &model.InvoiceListResponse{
-		Invoices:    invoiceResponses,
-		TotalProfit: totalProfit,
-		TotalCash:   totalCash,
-		Paging: model.PageMetadata{
-			Page:      page,
-			Size:      size,
-			TotalItem: totalItems,
-			TotalPage: totalPages,
-		},
-	}, nil
+	return converter.InvoiceToResponse(invoice), nil
 }
 
 func (c *InvoiceUseCase) Create(ctx context.Context, request *model.CreateInvoiceRequest) (*model.InvoiceResponse, error) {
@@ -320,6 +264,18 @@ func (c *InvoiceUseCase) Create(ctx context.Context, request *model.CreateInvoic
 		c.Log.WithError(err).Warn("Invalid date format for create invoice")
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid date format, use YYYY-MM-DD")
 	}
+	customerID, customerName, customerTerms, err := c.resolveCustomer(tx, request.CustomerID, request.CustomerName)
+	if err != nil {
+		return nil, err
+	}
+	salespersonID, salespersonName, err := c.resolveSalesperson(tx, request.SalespersonID, request.SalespersonName)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := paymentTerms(request.PaymentType, request.PaymentTerms, customerTerms)
+	if err != nil {
+		return nil, err
+	}
 
 	existing := new(entity.Invoice)
 	if err := c.InvoiceRepository.FindByInvoiceNo(tx, existing, request.InvoiceNo); err == nil {
@@ -333,10 +289,14 @@ func (c *InvoiceUseCase) Create(ctx context.Context, request *model.CreateInvoic
 	invoice := &entity.Invoice{
 		InvoiceNo:       request.InvoiceNo,
 		Date:            date,
-		CustomerName:    request.CustomerName,
-		SalespersonName: request.SalespersonName,
+		CustomerID:      customerID,
+		CustomerName:    customerName,
+		SalespersonID:   salespersonID,
+		SalespersonName: salespersonName,
 		PaymentType:     request.PaymentType,
 		Notes:           request.Notes,
+		PaymentTerms:    terms,
+		Status:          model.InvoiceStatusDraft,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
@@ -387,24 +347,38 @@ func (c *InvoiceUseCase) Update(ctx context.Context, invoiceNo string, request *
 		return nil, fiber.ErrInternalServerError
 	}
 
+	if invoice.Status != model.InvoiceStatusDraft {
+		return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Invoice is %s, only draft invoices can be edited", invoice.Status))
+	}
+
 	date, err := time.Parse("2006-01-02", request.Date)
 	if err != nil {
 		c.Log.WithError(err).WithField("invoice_no", invoiceNo).Warn("Invalid date format for update invoice")
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid date format, use YYYY-MM-DD")
 	}
+	customerID, customerName, customerTerms, err := c.resolveCustomer(tx, request.CustomerID, request.CustomerName)
+	if err != nil {
+		return nil, err
+	}
+	salespersonID, salespersonName, err := c.resolveSalesperson(tx, request.SalespersonID, request.SalespersonName)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := paymentTerms(request.PaymentType, request.PaymentTerms, customerTerms)
+	if err != nil {
+		return nil, err
+	}
 
 	invoice.Date = date
-	invoice.CustomerName = request.CustomerName
-	invoice.SalespersonName = request.SalespersonName
+	invoice.CustomerID = customerID
+	invoice.CustomerName = customerName
+	invoice.SalespersonID = salespersonID
+	invoice.SalespersonName = salespersonName
 	invoice.PaymentType = request.PaymentType
 	invoice.Notes = request.Notes
+	invoice.PaymentTerms = terms
 	invoice.UpdatedAt = time.Now()
 
-	if err := tx.Where("invoice_no = ?", invoice.InvoiceNo).Delete(&entity.Product{}).Error; err != nil {
-		c.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to delete old products before update")
-		return nil, fiber.ErrInternalServerError
-	}
-
 	newProducts := make([]entity.Product, 0, len(request.Products))
 	for _, p := range request.Products {
 		newProducts = append(newProducts, entity.Product{
@@ -419,7 +393,7 @@ func (c *InvoiceUseCase) Update(ctx context.Context, invoiceNo string, request *
 	}
 	invoice.Products = newProducts
 
-	if err := tx.Save(invoice).Error; err != nil {
+	if err := c.InvoiceRepository.Replace(tx, invoice); err != nil {
 		c.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to update invoice")
 		return nil, fiber.ErrInternalServerError
 	}
@@ -432,6 +406,76 @@ func (c *InvoiceUseCase) Update(ctx context.Context, invoiceNo string, request *
 	return converter.InvoiceToResponse(invoice), nil
 }
 
+// resolveCustomer returns the customer ID, name and payment terms a created
+// or edited invoice records. An id must belong to a customer. A name is
+// matched ignoring case, punctuation and spacing and recorded as registered;
+// a name matching none is kept as given, without an ID, with
+// model.DefaultPaymentTerms.
+func (c *InvoiceUseCase) resolveCustomer(tx *gorm.DB, id, name string) (*string, string, int, error) {
+	customer := new(entity.Customer)
+	if id != "" {
+		if err := c.CustomerRepository.FindById(tx, customer, id); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, "", 0, fiber.NewError(fiber.StatusBadRequest, "customer_id does not match any customer")
+			}
+			return nil, "", 0, fiber.ErrInternalServerError
+		}
+		return &customer.ID, customer.Name, customer.PaymentTerms, nil
+	}
+
+	customers, err := c.CustomerRepository.FindByMatch(tx, model.CustomerMatchNormalized, []string{name})
+	if err != nil {
+		return nil, "", 0, fiber.ErrInternalServerError
+	}
+	if len(customers) == 0 {
+		c.Log.WithField("customer_name", name).Warn("Invoice customer is not registered")
+		return nil, name, model.DefaultPaymentTerms, nil
+	}
+	return &customers[0].ID, customers[0].Name, customers[0].PaymentTerms, nil
+}
+
+// resolveSalesperson returns the salesperson ID and name a created or edited
+// invoice records. An id must belong to a salesperson. A name is matched
+// ignoring case, punctuation and spacing and recorded as registered; a name
+// matching none is kept as given, without an ID, and earns no commission.
+func (c *InvoiceUseCase) resolveSalesperson(tx *gorm.DB, id, name string) (*string, string, error) {
+	salesperson := new(entity.Salesperson)
+	if id != "" {
+		if err := c.SalespersonRepository.FindById(tx, salesperson, id); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, "", fiber.NewError(fiber.StatusBadRequest, "salesperson_id does not match any salesperson")
+			}
+			return nil, "", fiber.ErrInternalServerError
+		}
+		return &salesperson.ID, salesperson.Name, nil
+	}
+
+	salespersons, err := c.SalespersonRepository.FindByNames(tx, []string{name})
+	if err != nil {
+		return nil, "", fiber.ErrInternalServerError
+	}
+	if len(salespersons) == 0 {
+		return nil, name, nil
+	}
+	return &salespersons[0].ID, salespersons[0].Name, nil
+}
+
+// paymentTerms returns the terms of a created or edited invoice. CREDIT
+// invoices default to the customer's terms, and CASH invoices, which are
+// paid at the sale, cannot be given any.
+func paymentTerms(paymentType string, terms *int, customerTerms int) (int, error) {
+	if paymentType != "CREDIT" {
+		if terms != nil && *terms != 0 {
+			return 0, fiber.NewError(fiber.StatusBadRequest, "payment_terms only apply to CREDIT invoices")
+		}
+		return 0, nil
+	}
+	if terms == nil {
+		return customerTerms, nil
+	}
+	return *terms, nil
+}
+
 func (c *InvoiceUseCase) Delete(ctx context.Context, request *model.DeleteInvoiceRequest) error {
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Warn("Invalid delete invoice payload")
@@ -451,6 +495,14 @@ func (c *InvoiceUseCase) Delete(ctx context.Context, request *model.DeleteInvoic
 		return fiber.ErrInternalServerError
 	}
 
+	switch invoice.Status {
+	case model.InvoiceStatusDraft:
+	case model.InvoiceStatusIssued:
+		return fiber.NewError(fiber.StatusConflict, "Invoice is issued, void it instead of deleting it")
+	default:
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Invoice is %s and can no longer be deleted", invoice.Status))
+	}
+
 	if err := c.InvoiceRepository.Delete(tx, invoice); err != nil {
 		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to delete invoice")
 		return fiber.ErrInternalServerError
@@ -463,3 +515,69 @@ func (c *InvoiceUseCase) Delete(ctx context.Context, request *model.DeleteInvoic
 
 	return nil
 }
+
+// invoiceTransitions lists the statuses each status can move to.
+var invoiceTransitions = map[string][]string{
+	model.InvoiceStatusDraft:  {model.InvoiceStatusIssued},
+	model.InvoiceStatusIssued: {model.InvoiceStatusPaid, model.InvoiceStatusVoid},
+}
+
+// ChangeStatus moves an invoice along its lifecycle. Only a draft with at
+// least one product can be issued, and only an issued invoice can be paid or
+// voided: paid once nothing is outstanding, voided while it has no payments.
+func (c *InvoiceUseCase) ChangeStatus(ctx context.Context, request *model.ChangeInvoiceStatusRequest) (*model.InvoiceResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Warn("Invalid invoice status change")
+		return nil, fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	invoice := new(entity.Invoice)
+	err := c.InvoiceRepository.LockByInvoiceNo(tx, request.InvoiceNo)
+	if err == nil {
+		err = c.InvoiceRepository.FindByInvoiceNo(tx, invoice, request.InvoiceNo)
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to fetch invoice for status change")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if !slices.Contains(invoiceTransitions[invoice.Status], request.Status) {
+		return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Invoice is %s and cannot become %s", invoice.Status, request.Status))
+	}
+	if request.Status == model.InvoiceStatusIssued && len(invoice.Products) == 0 {
+		return nil, fiber.NewError(fiber.StatusConflict, "Invoice has no products to issue")
+	}
+	if request.Status == model.InvoiceStatusVoid && invoice.PaidAmount.IsPositive() {
+		return nil, fiber.NewError(fiber.StatusConflict, "Invoice has payments and cannot be voided")
+	}
+	if outstanding := converter.InvoiceToResponse(invoice).Outstanding; request.Status == model.InvoiceStatusPaid && outstanding.IsPositive() {
+		return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Invoice has an outstanding balance of %s, record a payment instead", outstanding.StringFixed(2)))
+	}
+
+	from := invoice.Status
+	changed, err := c.InvoiceRepository.UpdateStatus(tx, invoice, request.Status)
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+	if !changed {
+		return nil, fiber.NewError(fiber.StatusConflict, "Invoice status was changed by another request, try again")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to commit invoice status change")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	c.Log.WithFields(logrus.Fields{
+		"invoice_no": invoice.InvoiceNo,
+		"from":       from,
+		"to":         invoice.Status,
+	}).Info("Invoice status changed")
+	return converter.InvoiceToResponse(invoice), nil
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestInvoiceCursorRoundTrips(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	invoice := &entity.Invoice{
+		InvoiceNo:    "INV-000001",
+		Date:         time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+		CustomerName: "Customer 1",
+	}
+	sort := []model.SortField{{Key: "date", Desc: true}, {Key: "customer_name"}}
+
+	cursor, err := decodeInvoiceCursor(useCase.encodeInvoiceCursor(invoice, sort, true))
+	if err != nil {
+		t.Fatalf("decodeInvoiceCursor: %v", err)
+	}
+	if cursor.Sort != "-date,customer_name" || !cursor.Before ||
+		!slices.Equal(cursor.Values, []string{"2025-09-01", "Customer 1", "INV-000001"}) {
+		t.Errorf("cursor = %+v, want -date,customer_name before 2025-09-01, Customer 1, INV-000001", cursor)
+	}
+}
+
+func TestGetInvoicesRejectsTamperedCursors(t *testing.T) {
+	useCase := newTestInvoiceUseCase(t)
+	encode := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{"not base64", "not a cursor!", ""},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"-created_at"}`)), ""},
+		{"not json", encode("-created_at"), ""},
+		{"wrong types", encode(`{"s":"-created_at","v":[1,2]}`), ""},
+		{"unknown sort key", encode(`{"s":"id;DROP TABLE invoices","v":["1","INV-1"]}`), ""},
+		{"other sort", encode(`{"s":"-created_at","v":["2025-09-01T00:00:00Z","INV-1"]}`), "date"},
+		{"missing values", encode(`{"s":"-created_at","v":["2025-09-01T00:00:00Z"]}`), ""},
+		{"bad timestamp", encode(`{"s":"-created_at","v":["yesterday","INV-1"]}`), ""},
+		{"bad amount", encode(`{"s":"total","v":["1 OR 1=1","INV-1"]}`), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &model.SearchInvoiceRequest{Cursor: tt.cursor, Sort: tt.sort}
+			_, err := useCase.GetInvoices(context.Background(), request)
+			var fiberErr *fiber.Error
+			if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+				t.Errorf("GetInvoices with cursor %q: error = %v, want 400", tt.cursor, err)
+			}
+		})
+	}
+}
+
+func TestParseInvoiceSort(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []model.SortField
+	}{
+		{"", []model.SortField{{Key: "created_at", Desc: true}}},
+		{"  ", []model.SortField{{Key: "created_at", Desc: true}}},
+		{"total", []model.SortField{{Key: "total"}}},
+		{"-date, customer_name ,total", []model.SortField{{Key: "date", Desc: true}, {Key: "customer_name"}, {Key: "total"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseInvoiceSort(tt.value)
+		if err != nil {
+			t.Errorf("parseInvoiceSort(%q): %v", tt.value, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseInvoiceSort(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvoiceSortRejectsUnknownKeys(t *testing.T) {
+	for _, value := range []string{
+		"id",
+		"Date",
+		"--date",
+		"+date",
+		"date,",
+		"date,date",
+		"date,-date",
+		"date DESC",
+		"date; DROP TABLE invoices",
+		"(SELECT password FROM users)",
+		"invoices.date",
+		"total_price",
+	} {
+		if _, err := parseInvoiceSort(value); err == nil {
+			t.Errorf("parseInvoiceSort(%q) succeeded, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"golang-technical-challenge/internal/repository"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PaymentUseCase struct {
+	DB                *gorm.DB
+	Log               *logrus.Logger
+	Validate          *validator.Validate
+	PaymentRepository *repository.PaymentRepository
+	InvoiceRepository *repository.InvoiceRepository
+}
+
+func NewPaymentUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	paymentRepository *repository.PaymentRepository, invoiceRepository *repository.InvoiceRepository,
+) *PaymentUseCase {
+	return &PaymentUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		PaymentRepository: paymentRepository,
+		InvoiceRepository: invoiceRepository,
+	}
+}
+
+// Create records a payment against an issued CREDIT invoice, dated between
+// the invoice date and today. The invoice is locked while the payment is
+// checked against its outstanding balance, so concurrent payments cannot
+// overpay it together. A payment that settles the balance marks the invoice
+// paid, and any other payment still bumps the invoice's updated_at, so the
+// import that created it can no longer be undone.
+func (c *PaymentUseCase) Create(ctx context.Context, request *model.CreatePaymentRequest) (*model.PaymentResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Warn("Invalid create payment payload")
+		return nil, fiber.ErrBadRequest
+	}
+	if !request.Amount.IsPositive() || !request.Amount.Equal(request.Amount.Round(2)) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "amount must be positive with at most two decimal places")
+	}
+
+	date, err := time.Parse("2006-01-02", request.Date)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid date format, use YYYY-MM-DD")
+	}
+	if request.Date > time.Now().Format("2006-01-02") {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Payment date cannot be in the future")
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	invoice := new(entity.Invoice)
+	err = c.InvoiceRepository.LockByInvoiceNo(tx, request.InvoiceNo)
+	if err == nil {
+		err = c.InvoiceRepository.FindByInvoiceNo(tx, invoice, request.InvoiceNo)
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to fetch invoice for payment")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if invoice.PaymentType != "CREDIT" {
+		return nil, fiber.NewError(fiber.StatusConflict, "Payments are only recorded for CREDIT invoices, CASH invoices are paid at the sale")
+	}
+	if invoice.Status != model.InvoiceStatusIssued {
+		return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Invoice is %s, payments are only recorded for issued invoices", invoice.Status))
+	}
+	if invoiceDate := invoice.Date.Format("2006-01-02"); request.Date < invoiceDate {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Payment date cannot be before the invoice date %s", invoiceDate))
+	}
+	outstanding := converter.InvoiceToResponse(invoice).Outstanding
+	if request.Amount.GreaterThan(outstanding) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Payment of %s exceeds the outstanding balance of %s",
+			request.Amount.StringFixed(2), outstanding.StringFixed(2)))
+	}
+
+	payment := &entity.Payment{
+		InvoiceNo: invoice.InvoiceNo,
+		Date:      date,
+		Amount:    request.Amount,
+		Method:    request.Method,
+		Reference: request.Reference,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := c.PaymentRepository.Create(tx, payment); err != nil {
+		c.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to create payment")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if request.Amount.Equal(outstanding) {
+		if _, err := c.InvoiceRepository.UpdateStatus(tx, invoice, model.InvoiceStatusPaid); err != nil {
+			return nil, fiber.ErrInternalServerError
+		}
+	} else if err := c.InvoiceRepository.Touch(tx, invoice); err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("invoice_no", invoice.InvoiceNo).Error("Failed to commit payment")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.PaymentToResponse(payment), nil
+}
+
+func (c *PaymentUseCase) List(ctx context.Context, request *model.ListPaymentRequest) ([]model.PaymentResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Warn("Invalid list payments request")
+		return nil, fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx)
+
+	invoice := new(entity.Invoice)
+	if err := c.InvoiceRepository.FindByInvoiceNo(tx, invoice, request.InvoiceNo); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		c.Log.WithError(err).WithField("invoice_no", request.InvoiceNo).Error("Failed to fetch invoice for payments")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	payments, err := c.PaymentRepository.FindByInvoiceNo(tx, request.InvoiceNo)
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.PaymentsToResponseList(payments), nil
+}
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang-technical-challenge/internal/entity"
+	"golang-technical-challenge/internal/model"
+	"golang-technical-challenge/internal/model/converter"
+	"golang-technical-challenge/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SalespersonUseCase struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	SalespersonRepository *repository.SalespersonRepository
+}
+
+func NewSalespersonUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	salespersonRepository *repository.SalespersonRepository,
+) *SalespersonUseCase {
+	return &SalespersonUseCase{
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		SalespersonRepository: salespersonRepository,
+	}
+}
+
+func (c *SalespersonUseCase) Create(ctx context.Context, request *model.CreateSalespersonRequest) (*model.SalespersonResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid create salesperson payload")
+		return nil, fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	salesperson := &entity.Salesperson{CreatedAt: time.Now()}
+	if err := c.applySalesperson(tx, salesperson, request.Code, request.Name, ""); err != nil {
+		return nil, err
+	}
+	salesperson.Email = request.Email
+	salesperson.Phone = request.Phone
+	if err := c.applyCommission(salesperson, request.CommissionBasis, request.CommissionTiers); err != nil {
+		return nil, err
+	}
+
+	if err := c.SalespersonRepository.Create(tx, salesperson); err != nil {
+		c.Log.WithError(err).WithField("code", salesperson.Code).Error("Failed to create salesperson")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("code", salesperson.Code).Error("Failed to commit salesperson creation")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SalespersonToResponse(salesperson), nil
+}
+
+func (c *SalespersonUseCase) Get(ctx context.Context, request *model.GetSalespersonRequest) (*model.SalespersonResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid get salesperson request")
+		return nil, fiber.ErrBadRequest
+	}
+
+	salesperson := new(entity.Salesperson)
+	if err := c.SalespersonRepository.FindById(c.DB.WithContext(ctx), salesperson, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SalespersonToResponse(salesperson), nil
+}
+
+func (c *SalespersonUseCase) Search(ctx context.Context, request *model.SearchSalespersonRequest) ([]model.SalespersonResponse, *model.PageMetadata, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid search salesperson request")
+		return nil, nil, fiber.ErrBadRequest
+	}
+
+	offset := (request.Page - 1) * request.Size
+	salespersons, totalItems, err := c.SalespersonRepository.Search(c.DB.WithContext(ctx), request, request.Size, offset)
+	if err != nil {
+		return nil, nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SalespersonsToResponseList(salespersons), &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: totalItems,
+		TotalPage: (totalItems + int64(request.Size) - 1) / int64(request.Size),
+	}, nil
+}
+
+// Update edits a salesperson. Its invoices keep the name they were made with,
+// and the statements of closed months keep the rules they were paid under.
+func (c *SalespersonUseCase) Update(ctx context.Context, request *model.UpdateSalespersonRequest) (*model.SalespersonResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).WithField("id", request.ID).Warn("Invalid update salesperson payload")
+		return nil, fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	salesperson := new(entity.Salesperson)
+	if err := c.SalespersonRepository.FindById(tx, salesperson, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.ErrNotFound
+		}
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.applySalesperson(tx, salesperson, request.Code, request.Name, salesperson.ID); err != nil {
+		return nil, err
+	}
+	salesperson.Email = request.Email
+	salesperson.Phone = request.Phone
+	if err := c.applyCommission(salesperson, request.CommissionBasis, request.CommissionTiers); err != nil {
+		return nil, err
+	}
+	salesperson.UpdatedAt = time.Now()
+
+	if err := c.SalespersonRepository.Update(tx, salesperson); err != nil {
+		c.Log.WithError(err).WithField("id", salesperson.ID).Error("Failed to update salesperson")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("id", salesperson.ID).Error("Failed to commit salesperson update")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SalespersonToResponse(salesperson), nil
+}
+
+// Delete removes a salesperson that no invoice or commission statement
+// references.
+func (c *SalespersonUseCase) Delete(ctx context.Context, request *model.DeleteSalespersonRequest) error {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("Invalid delete salesperson request")
+		return fiber.ErrBadRequest
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	salesperson := new(entity.Salesperson)
+	if err := c.SalespersonRepository.FindById(tx, salesperson, request.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fiber.ErrNotFound
+		}
+		return fiber.ErrInternalServerError
+	}
+
+	invoices, statements, err := c.SalespersonRepository.CountReferences(tx, salesperson.ID)
+	if err != nil {
+		return fiber.ErrInternalServerError
+	}
+	if invoices > 0 || statements > 0 {
+		return fiber.NewError(fiber.StatusConflict,
+			fmt.Sprintf("Salesperson has %d invoices and %d commission statements and cannot be deleted", invoices, statements))
+	}
+
+	if err := c.SalespersonRepository.Delete(tx, salesperson); err != nil {
+		c.Log.WithError(err).WithField("id", salesperson.ID).Error("Failed to delete salesperson")
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).WithField("id", salesperson.ID).Error("Failed to commit salesperson deletion")
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// applySalesperson sets the code and name of salesperson after checking
+// that no other salesperson has the code or, ignoring case, punctuation and
+// spacing, the name.
+func (c *SalespersonUseCase) applySalesperson(tx *gorm.DB, salesperson *entity.Salesperson, code, name, id string) error {
+	code, name = strings.TrimSpace(code), strings.TrimSpace(name)
+	if code == "" || entity.NameKey(name) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "code must not be blank and name must contain letters or digits")
+	}
+
+	existing := new(entity.Salesperson)
+	if err := c.SalespersonRepository.FindConflict(tx, existing, code, name, id); err == nil {
+		if existing.Code == code {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Salesperson code %s is already used by %s", code, existing.Name))
+		}
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Salesperson %s (%s) already has this name", existing.Name, existing.Code))
+	} else if err != gorm.ErrRecordNotFound {
+		c.Log.WithError(err).WithField("code", code).Error("Failed to check existing salespersons")
+		return fiber.ErrInternalServerError
+	}
+
+	salesperson.Code = code
+	salesperson.Name = name
+	return nil
+}
+
+// applyCommission sets the commission rules of salesperson. Tiers must have
+// distinct minimum volumes of zero or more and rates between 0 and 100
+// percent with at most two decimals; they are stored in ascending order of
+// minimum volume.
+func (c *SalespersonUseCase) applyCommission(salesperson *entity.Salesperson, basis string, tiers []model.CommissionTier) error {
+	salesperson.CommissionBasis = defaultString(basis, model.CommissionBasisProfit)
+
+	sorted := slices.Clone(tiers)
+	if sorted == nil {
+		sorted = []model.CommissionTier{}
+	}
+	slices.SortFunc(sorted, func(a, b model.CommissionTier) int {
+		return a.MinVolume.Cmp(b.MinVolume)
+	})
+	for i, tier := range sorted {
+		if tier.MinVolume.IsNegative() {
+			return fiber.NewError(fiber.StatusBadRequest, "commission tier min_volume must not be negative")
+		}
+		if tier.Rate.IsNegative() || tier.Rate.GreaterThan(decimal.NewFromInt(100)) || !tier.Rate.Equal(tier.Rate.Round(2)) {
+			return fiber.NewError(fiber.StatusBadRequest, "commission tier rate must be a percentage between 0 and 100 with at most two decimals")
+		}
+		if i > 0 && tier.MinVolume.Equal(sorted[i-1].MinVolume) {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Two commission tiers start at min_volume %s", tier.MinVolume))
+		}
+	}
+
+	encoded, err := json.Marshal(sorted)
+	if err != nil {
+		c.Log.WithError(err).Error("Failed to encode commission tiers")
+		return fiber.ErrInternalServerError
+	}
+	salesperson.CommissionTiers = string(encoded)
+	return nil
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// dateLayouts names the datetime= layouts of the request models the way
+// clients read them.
+var dateLayouts = map[string]string{
+	"2006-01-02":                "a date formatted as YYYY-MM-DD",
+	"2006-01":                   "a month formatted as YYYY-MM",
+	"2006-01-02T15:04:05Z07:00": "an RFC 3339 timestamp",
+}
+
+// validationMessage describes the fields err, returned by Validate.Struct,
+// rejected, after prefix. Allowed values and formats come from the failed
+// validate tags, so the message stays in step with the models.
+func validationMessage(prefix string, err error) string {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return prefix
+	}
+
+	problems := make([]string, len(fieldErrors))
+	for i, fe := range fieldErrors {
+		problems[i] = fieldProblem(fe)
+	}
+	return prefix + ", " + strings.Join(problems, ", ")
+}
+
+// fieldProblem describes one failed validate tag.
+func fieldProblem(fe validator.FieldError) string {
+	field := fe.Field()
+	switch fe.Tag() {
+	case "required", "required_without":
+		return field + " is required"
+	case "oneof":
+		return field + " must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "datetime":
+		if layout, ok := dateLayouts[fe.Param()]; ok {
+			return field + " must be " + layout
+		}
+		return field + " must be formatted as " + fe.Param()
+	case "numeric":
+		return field + " must be a number"
+	case "uuid":
+		return field + " must be a UUID"
+	case "max":
+		return fmt.Sprintf("%s must be at most %s%s", field, fe.Param(), unit(fe))
+	case "min":
+		return fmt.Sprintf("%s must be at least %s%s", field, fe.Param(), unit(fe))
+	}
+	return field + " is invalid"
+}
+
+// unit is what the min and max of fe count: characters of a string, items of
+// a list, nothing for a number.
+func unit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map:
+		return " items"
+	}
+	return ""
+}
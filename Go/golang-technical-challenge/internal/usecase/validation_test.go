@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"errors"
+	"golang-technical-challenge/internal/model"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestValidationMessage(t *testing.T) {
+	validate := validator.New()
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{
+			&model.ImportOptions{Mode: "all", OnConflict: "replace", Locale: "nl-NL"},
+			"Invalid, Mode must be one of atomic, best_effort, Locale must be one of en-US, en-GB, id-ID, de-DE, fr-FR",
+		},
+		{
+			&model.ImportOptions{Mode: "atomic"},
+			"Invalid, OnConflict is required",
+		},
+		{
+			&model.InvoiceFilter{Date: "01/09/2025", Status: "open", MinTotal: "ten"},
+			"Invalid, Date must be a date formatted as YYYY-MM-DD, Status must be one of draft, issued, paid, void, MinTotal must be a number",
+		},
+	}
+	for _, tt := range tests {
+		if got := validationMessage("Invalid", validate.Struct(tt.value)); got != tt.want {
+			t.Errorf("validationMessage(%+v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	if got := validationMessage("Invalid", errors.New("not a field error")); got != "Invalid" {
+		t.Errorf("validationMessage of another error = %q, want the prefix alone", got)
+	}
+}